@@ -11,7 +11,7 @@ import (
 )
 
 func main() {
-	stmts, err := gormschema.New("postgres").Load(&entity.User{}, &entity.Profile{}, &entity.Post{})
+	stmts, err := gormschema.New("postgres").Load(&entity.User{}, &entity.Profile{}, &entity.Post{}, &entity.Tag{}, &entity.PostRevision{}, &entity.PostSlug{}, &entity.Comment{}, &entity.PostReaction{}, &entity.Bookmark{}, &entity.Follow{}, &entity.Media{}, &entity.MediaVariant{}, &entity.RefreshToken{}, &entity.RevokedToken{}, &entity.UserRevocation{}, &entity.Session{})
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to load gorm schema: %v\n", err)
 		os.Exit(1)
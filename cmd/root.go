@@ -16,6 +16,7 @@ func Execute() {
 	}
 
 	command.AddCommand(apiCmd())
+	command.AddCommand(schedulerCmd())
 
 	if err := command.Execute(); err != nil {
 		log.Fatal().Msgf("failed run app: %s", err.Error())
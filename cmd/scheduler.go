@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"post/internal/pkg/cache"
+	"post/internal/pkg/config"
+	"post/internal/pkg/database"
+	"post/internal/pkg/logger"
+	"post/internal/post"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+func schedulerCmd() *cobra.Command {
+	var command = &cobra.Command{
+		Use:   "scheduler",
+		Short: "Run scheduled post publisher",
+		Long:  `Publishes scheduled posts once their publish_at has passed. Safe to run next to API servers and other scheduler replicas.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg := config.LoadConfig()
+			logger.InitLogger(cfg.App.Env)
+			database.Connect(cfg)
+
+			postCache, err := cache.NewLRUCache(100)
+			if err != nil {
+				log.Fatal().Msgf("failed create cache: %s", err.Error())
+			}
+			postService := post.NewService(post.NewRepository(database.GetDB()), postCache)
+			interval := time.Duration(cfg.Scheduler.Interval) * time.Second
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			log.Info().Dur("interval", interval).Msg("Scheduler started")
+			post.NewScheduler(postService, interval, true).Run(ctx)
+			log.Info().Msg("Scheduler stopped")
+		},
+	}
+
+	return command
+}
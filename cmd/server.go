@@ -8,15 +8,19 @@ import (
 
 func apiCmd() *cobra.Command {
 	var port int
+	var publishScheduled bool
+	var processMedia bool
 	var command = &cobra.Command{
 		Use:   "api",
 		Short: "Run api server",
 		Run: func(cmd *cobra.Command, args []string) {
-			srv := api.NewServer()
+			srv := api.NewServer(publishScheduled, processMedia)
 			srv.Run(port)
 		},
 	}
 
 	command.Flags().IntVar(&port, "port", 8080, "Listen on given port")
+	command.Flags().BoolVar(&publishScheduled, "scheduler", true, "Publish scheduled posts from this process")
+	command.Flags().BoolVar(&processMedia, "media-worker", true, "Process uploaded post images from this process")
 	return command
 }
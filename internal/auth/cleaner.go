@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Cleaner periodically deletes revocations and refresh tokens that have
+// expired, so the tables only hold tokens that could still be used. Running
+// one in every process is harmless.
+type Cleaner struct {
+	repo     Repository
+	interval time.Duration
+}
+
+func NewCleaner(repo Repository, interval time.Duration) *Cleaner {
+	return &Cleaner{repo, interval}
+}
+
+// Run blocks until ctx is cancelled.
+func (c *Cleaner) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.tick()
+		}
+	}
+}
+
+func (c *Cleaner) tick() {
+	count, err := c.repo.DeleteExpired(time.Now())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to delete expired tokens")
+		return
+	}
+	if count > 0 {
+		log.Info().Int64("count", count).Msg("Deleted expired tokens")
+	}
+}
@@ -4,10 +4,12 @@ import (
 	"errors"
 	"net/http"
 
-	pkgdb "post/internal/pkg/database"
+	"post/internal/entity"
 	"post/internal/pkg/response"
+	"post/internal/user"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type Handler struct {
@@ -25,9 +27,9 @@ func (h *Handler) Signup(c *gin.Context) {
 		return
 	}
 
-	user, err := h.service.Signup(input)
+	newUser, err := h.service.Signup(input)
 	if err != nil {
-		if errors.Is(err, pkgdb.ErrDuplicateKey) {
+		if errors.Is(err, ErrEmailTaken) {
 			response.Error(c, http.StatusUnprocessableEntity, "Email already registered", nil)
 			return
 		}
@@ -35,7 +37,7 @@ func (h *Handler) Signup(c *gin.Context) {
 		return
 	}
 
-	response.Success(c, http.StatusCreated, "User registered successfully", user)
+	response.Success(c, http.StatusCreated, "User registered successfully", user.NewSelfUser(newUser))
 }
 
 func (h *Handler) Signin(c *gin.Context) {
@@ -45,7 +47,7 @@ func (h *Handler) Signin(c *gin.Context) {
 		return
 	}
 
-	token, refreshToken, err := h.service.Signin(input)
+	token, refreshToken, err := h.service.Signin(input, clientOf(c))
 	if err != nil {
 		response.Error(c, http.StatusUnauthorized, "Login failed", err.Error())
 		return
@@ -56,3 +58,108 @@ func (h *Handler) Signin(c *gin.Context) {
 		"refresh_token": refreshToken,
 	})
 }
+
+type RefreshInput struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// Refresh trades a refresh token for a new token pair. The refresh token sent
+// is used up and must be replaced with the one returned.
+func (h *Handler) Refresh(c *gin.Context) {
+	var input RefreshInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+
+	token, refreshToken, err := h.service.Refresh(input.RefreshToken, clientOf(c))
+	if err != nil {
+		if errors.Is(err, ErrInvalidRefreshToken) || errors.Is(err, ErrRefreshTokenReused) {
+			response.Error(c, http.StatusUnauthorized, "Refresh failed", err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to refresh token", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Token refreshed", gin.H{
+		"token":         token,
+		"refresh_token": refreshToken,
+	})
+}
+
+// Logout revokes the access token sent with the request and the refresh
+// tokens from the same signin.
+func (h *Handler) Logout(c *gin.Context) {
+	err := h.service.Logout(c.GetUint("userID"), c.GetString("tokenID"), c.GetString("sessionID"), c.GetTime("tokenExpiresAt"))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to log out", err.Error())
+		return
+	}
+	response.Success(c, http.StatusOK, "Logged out", nil)
+}
+
+// LogoutAll revokes every token of the caller, on every device.
+func (h *Handler) LogoutAll(c *gin.Context) {
+	if err := h.service.LogoutAll(c.GetUint("userID")); err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to log out", err.Error())
+		return
+	}
+	response.Success(c, http.StatusOK, "Logged out everywhere", nil)
+}
+
+// SessionView is a session as its user sees it.
+type SessionView struct {
+	entity.Session
+	// Current marks the session the request was made with.
+	Current bool `json:"current"`
+}
+
+// GetSessions lists the devices the caller is signed in on.
+func (h *Handler) GetSessions(c *gin.Context) {
+	sessions, err := h.service.GetSessions(c.GetUint("userID"))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to retrieve sessions", err.Error())
+		return
+	}
+
+	views := make([]SessionView, len(sessions))
+	for i, session := range sessions {
+		views[i] = SessionView{Session: session, Current: session.ID == c.GetString("sessionID")}
+	}
+	response.Success(c, http.StatusOK, "Sessions retrieved", views)
+}
+
+// DeleteSession signs the caller out on one device. Its tokens stop working
+// right away.
+func (h *Handler) DeleteSession(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid ID", err.Error())
+		return
+	}
+
+	if err := h.service.RevokeSession(c.GetUint("userID"), id.String()); err != nil {
+		if errors.Is(err, ErrSessionNotFound) {
+			response.Error(c, http.StatusNotFound, "Session not found", nil)
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to revoke session", err.Error())
+		return
+	}
+	response.Success(c, http.StatusOK, "Session revoked", nil)
+}
+
+func clientOf(c *gin.Context) Client {
+	return Client{UserAgent: c.Request.UserAgent(), IP: c.ClientIP()}
+}
+
+// JWKS publishes the public signing keys as a JSON Web Key Set. It answers
+// with the bare set rather than the usual envelope, since JWT libraries
+// fetch it directly.
+func JWKS(jwtService JWTService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(http.StatusOK, jwtService.KeySet())
+	}
+}
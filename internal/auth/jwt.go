@@ -1,63 +1,128 @@
 package auth
 
 import (
+	"fmt"
 	"time"
 
 	"post/internal/entity"
 	"post/internal/pkg/config"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 type JWTService interface {
-	GenerateToken(user *entity.User) (string, error)
-	GenerateRefreshToken(user *entity.User) (string, error)
+	GenerateToken(user *entity.User, sessionID string) (string, error)
+	GenerateRefreshToken(user *entity.User, record *entity.RefreshToken) (string, error)
 	ValidateToken(tokenString string) (*jwt.Token, error)
+	// KeySet returns the public keys tokens are verified with. It is empty
+	// for HS256, whose secret is never published.
+	KeySet() KeySet
 }
 
 type jwtService struct {
-	secretKey     string
+	method    jwt.SigningMethod
+	secretKey string
+	// keys is nil for HS256.
+	keys          *keyring
 	expiry        int
 	refreshExpiry int
 }
 
-func NewJWTService(cfg *config.Config) JWTService {
-	return &jwtService{
+func NewJWTService(cfg *config.Config) (JWTService, error) {
+	j := &jwtService{
 		secretKey:     cfg.JWT.Secret,
 		expiry:        cfg.JWT.Expiry,
 		refreshExpiry: cfg.JWT.RefreshExpiry,
 	}
+
+	switch cfg.JWT.Algorithm {
+	case "HS256":
+		j.method = jwt.SigningMethodHS256
+	case "RS256", "EdDSA":
+		j.method = jwt.GetSigningMethod(cfg.JWT.Algorithm)
+		grace := time.Duration(cfg.JWT.KeyGracePeriod) * time.Hour
+		keys, err := loadKeyring(j.method, cfg.JWT.Keys, grace)
+		if err != nil {
+			return nil, err
+		}
+		j.keys = keys
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm %q", cfg.JWT.Algorithm)
+	}
+	return j, nil
 }
 
-func (j *jwtService) GenerateToken(user *entity.User) (string, error) {
+// GenerateToken signs an access token with a fresh jti, so it can be revoked
+// on its own, bound to the session it was issued for.
+func (j *jwtService) GenerateToken(user *entity.User, sessionID string) (string, error) {
+	now := time.Now()
 	claims := jwt.MapClaims{
 		"user_id": user.ID,
 		"role":    user.Role,
 		"type":    "access",
-		"exp":     time.Now().Add(time.Duration(j.expiry) * time.Hour).Unix(),
+		"jti":     uuid.NewString(),
+		"sid":     sessionID,
+		"iat":     now.Unix(),
+		"exp":     now.Add(time.Duration(j.expiry) * time.Hour).Unix(),
 	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(j.secretKey))
+	return j.sign(claims)
 }
 
-func (j *jwtService) GenerateRefreshToken(user *entity.User) (string, error) {
+// GenerateRefreshToken signs a refresh token carrying the record's ID as its
+// jti and its family as the session, and sets the record's expiry to match
+// the token.
+func (j *jwtService) GenerateRefreshToken(user *entity.User, record *entity.RefreshToken) (string, error) {
+	now := time.Now()
+	record.ExpiresAt = now.Add(time.Duration(j.refreshExpiry) * time.Hour)
 	claims := jwt.MapClaims{
 		"user_id": user.ID,
 		"role":    user.Role,
 		"type":    "refresh",
-		"exp":     time.Now().Add(time.Duration(j.refreshExpiry) * time.Hour).Unix(),
+		"jti":     record.ID,
+		"sid":     record.FamilyID,
+		"iat":     now.Unix(),
+		"exp":     record.ExpiresAt.Unix(),
+	}
+	return j.sign(claims)
+}
+
+// sign signs with the secret, or with the current key named in the kid
+// header.
+func (j *jwtService) sign(claims jwt.MapClaims) (string, error) {
+	token := jwt.NewWithClaims(j.method, claims)
+	if j.keys == nil {
+		return token.SignedString([]byte(j.secretKey))
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(j.secretKey))
+	key, err := j.keys.signer(time.Now())
+	if err != nil {
+		return "", err
+	}
+	token.Header["kid"] = key.id
+	return token.SignedString(key.private)
 }
 
+// ValidateToken only accepts the configured algorithm, so a token can never
+// pick how it is checked. Asymmetric tokens are checked with the key their
+// kid names, as long as that key is still within its grace period.
 func (j *jwtService) ValidateToken(tokenString string) (*jwt.Token, error) {
 	return jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		if j.keys == nil {
+			return []byte(j.secretKey), nil
+		}
+		kid, _ := token.Header["kid"].(string)
+		key, ok := j.keys.verifier(kid, time.Now())
+		if !ok {
 			return nil, jwt.ErrSignatureInvalid
 		}
-		return []byte(j.secretKey), nil
-	})
+		return key, nil
+	}, jwt.WithValidMethods([]string{j.method.Alg()}))
+}
+
+func (j *jwtService) KeySet() KeySet {
+	if j.keys == nil {
+		return KeySet{Keys: []JWK{}}
+	}
+	return j.keys.keySet(time.Now())
 }
@@ -0,0 +1,198 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"slices"
+	"time"
+
+	"post/internal/pkg/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// minRSABits is the smallest RSA key accepted for signing.
+const minRSABits = 2048
+
+var ErrNoSigningKey = errors.New("no signing key is active yet")
+
+// signingKey is one asymmetric key and when it starts signing.
+type signingKey struct {
+	id        string
+	private   crypto.Signer
+	notBefore time.Time
+}
+
+// keyring holds the asymmetric keys in the order they take over signing.
+// Keys that have not taken over yet are already published, so verifiers
+// have them before the first token signed with them shows up.
+type keyring struct {
+	method jwt.SigningMethod
+	keys   []signingKey
+	grace  time.Duration
+}
+
+// loadKeyring reads every configured key from its PEM file. All keys must
+// suit the signing method.
+func loadKeyring(method jwt.SigningMethod, keys []config.KeyConfig, grace time.Duration) (*keyring, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%s needs at least one key in JWT_KEYS", method.Alg())
+	}
+
+	ring := &keyring{method: method, grace: grace}
+	for _, k := range keys {
+		if slices.ContainsFunc(ring.keys, func(key signingKey) bool { return key.id == k.ID }) {
+			return nil, fmt.Errorf("duplicate key id %q", k.ID)
+		}
+		private, err := readPrivateKey(k.Path)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", k.ID, err)
+		}
+		if err := checkKey(method, private); err != nil {
+			return nil, fmt.Errorf("key %q: %w", k.ID, err)
+		}
+		ring.keys = append(ring.keys, signingKey{id: k.ID, private: private, notBefore: k.NotBefore})
+	}
+
+	// Keys without a start time sign from the beginning, in the order given
+	slices.SortStableFunc(ring.keys, func(a, b signingKey) int {
+		return a.notBefore.Compare(b.notBefore)
+	})
+	return ring, nil
+}
+
+func readPrivateKey(path string) (crypto.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var key any
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported key type %T", key)
+	}
+	return signer, nil
+}
+
+func checkKey(method jwt.SigningMethod, key crypto.Signer) error {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		if method != jwt.SigningMethodRS256 {
+			return fmt.Errorf("RSA key cannot sign %s", method.Alg())
+		}
+		if k.N.BitLen() < minRSABits {
+			return fmt.Errorf("RSA key must have at least %d bits", minRSABits)
+		}
+	case ed25519.PrivateKey:
+		if method != jwt.SigningMethodEdDSA {
+			return fmt.Errorf("Ed25519 key cannot sign %s", method.Alg())
+		}
+	default:
+		return fmt.Errorf("unsupported key type %T", key)
+	}
+	return nil
+}
+
+// signer returns the key that signs at now: the last one to have started.
+func (r *keyring) signer(now time.Time) (*signingKey, error) {
+	var current *signingKey
+	for i := range r.keys {
+		if r.keys[i].notBefore.After(now) {
+			break
+		}
+		current = &r.keys[i]
+	}
+	if current == nil {
+		return nil, ErrNoSigningKey
+	}
+	return current, nil
+}
+
+// usable reports whether the i-th key verifies tokens at now. A key stops
+// once the grace period after the next key took over has passed.
+func (r *keyring) usable(i int, now time.Time) bool {
+	if i+1 == len(r.keys) {
+		return true
+	}
+	replacedAt := r.keys[i+1].notBefore
+	return replacedAt.After(now) || now.Before(replacedAt.Add(r.grace))
+}
+
+// verifier returns the public key with the given kid, if it is usable.
+func (r *keyring) verifier(kid string, now time.Time) (crypto.PublicKey, bool) {
+	for i, key := range r.keys {
+		if key.id == kid {
+			if !r.usable(i, now) {
+				return nil, false
+			}
+			return key.private.Public(), true
+		}
+	}
+	return nil, false
+}
+
+// JWK is a public key as published in a JSON Web Key Set (RFC 7517).
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	// N and E are the modulus and exponent of an RSA key.
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Curve and X are the curve and public key of an Ed25519 key.
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+}
+
+// KeySet is a JSON Web Key Set.
+type KeySet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// keySet publishes every key that is usable at now, upcoming ones included.
+func (r *keyring) keySet(now time.Time) KeySet {
+	set := KeySet{Keys: []JWK{}}
+	for i, key := range r.keys {
+		if !r.usable(i, now) {
+			continue
+		}
+		jwk := JWK{KeyID: key.id, Use: "sig", Algorithm: r.method.Alg()}
+		switch public := key.private.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"net/http"
 	"strings"
+	"time"
 
 	"post/internal/entity"
 	"post/internal/pkg/response"
@@ -12,7 +13,12 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func Middleware(jwtService JWTService) gin.HandlerFunc {
+// Middleware authenticates the request by its access token, which must not
+// have been revoked. With silentRefresh set, an expired access token is replaced using the
+// X-Refresh-Token header and the new one returned in X-New-Token. That path
+// never rotates the refresh token, so it is deprecated in favour of
+// POST /api/auth/refresh.
+func Middleware(jwtService JWTService, service Service, silentRefresh bool) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -32,67 +38,60 @@ func Middleware(jwtService JWTService) gin.HandlerFunc {
 		tokenString := parts[1]
 		token, err := jwtService.ValidateToken(tokenString)
 
-		var newToken string
+		var claims jwt.MapClaims
 		if err != nil {
-			if errors.Is(err, jwt.ErrTokenExpired) {
-				// Try refresh
-				refreshToken := c.GetHeader("X-Refresh-Token")
-				if refreshToken == "" {
-					response.Error(c, http.StatusUnauthorized, "Token expired and no refresh token provided", nil)
-					c.Abort()
-					return
-				}
-
-				refreshTok, refreshErr := jwtService.ValidateToken(refreshToken)
-				if refreshErr != nil || !refreshTok.Valid {
-					response.Error(c, http.StatusUnauthorized, "Invalid or expired refresh token", nil)
-					c.Abort()
-					return
-				}
-
-				claims, ok := refreshTok.Claims.(jwt.MapClaims)
-				if !ok || claims["type"] != "refresh" {
-					response.Error(c, http.StatusUnauthorized, "Invalid refresh token", nil)
-					c.Abort()
-					return
-				}
-
-				// Generate new access token
-				userID, _ := claims["user_id"].(float64)
-				role, _ := claims["role"].(float64)
-
-				user := &entity.User{
-					ID:   uint(userID),
-					Role: entity.Role(role),
-				}
-
-				newToken, err = jwtService.GenerateToken(user)
-				if err != nil {
-					response.Error(c, http.StatusInternalServerError, "Failed to generate new token", nil)
-					c.Abort()
-					return
-				}
-
-				c.Header("X-New-Token", newToken)
-
-				// Use refresh token claims for context since they are valid and contain the same info
-				token = refreshTok
-			} else {
+			if !errors.Is(err, jwt.ErrTokenExpired) {
 				response.Error(c, http.StatusUnauthorized, "Invalid token", nil)
 				c.Abort()
 				return
 			}
-		} else if !token.Valid {
-			response.Error(c, http.StatusUnauthorized, "Invalid token", nil)
-			c.Abort()
-			return
-		}
+			if !silentRefresh {
+				response.Error(c, http.StatusUnauthorized, "Token expired", nil)
+				c.Abort()
+				return
+			}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			response.Error(c, http.StatusUnauthorized, "Invalid token claims", nil)
-			c.Abort()
-			return
+			// Try refresh
+			refreshToken := c.GetHeader("X-Refresh-Token")
+			if refreshToken == "" {
+				response.Error(c, http.StatusUnauthorized, "Token expired and no refresh token provided", nil)
+				c.Abort()
+				return
+			}
+
+			newToken, err := service.RefreshAccessToken(refreshToken)
+			if errors.Is(err, ErrInvalidRefreshToken) {
+				response.Error(c, http.StatusUnauthorized, "Invalid or expired refresh token", nil)
+				c.Abort()
+				return
+			}
+			if err != nil {
+				response.Error(c, http.StatusInternalServerError, "Failed to generate new token", nil)
+				c.Abort()
+				return
+			}
+
+			// Continue with the new token's claims, so the request runs as
+			// the token the client is about to use
+			newTok, err := jwtService.ValidateToken(newToken)
+			if err != nil {
+				response.Error(c, http.StatusInternalServerError, "Failed to generate new token", nil)
+				c.Abort()
+				return
+			}
+			claims, _ = newTok.Claims.(jwt.MapClaims)
+
+			c.Header("X-New-Token", newToken)
+			c.Header("Deprecation", "true")
+			c.Header("Link", `</api/auth/refresh>; rel="alternate"`)
+		} else {
+			var ok bool
+			claims, ok = token.Claims.(jwt.MapClaims)
+			if !token.Valid || !ok || claims["type"] != "access" {
+				response.Error(c, http.StatusUnauthorized, "Invalid token", nil)
+				c.Abort()
+				return
+			}
 		}
 
 		// Set userID and role in context
@@ -109,6 +108,61 @@ func Middleware(jwtService JWTService) gin.HandlerFunc {
 			c.Set("role", entity.Role(role))
 		}
 
+		// Tokens without a jti or session predate revocation and cannot be
+		// revoked
+		tokenID, _ := claims["jti"].(string)
+		sessionID, _ := claims["sid"].(string)
+		if tokenID == "" || sessionID == "" {
+			response.Error(c, http.StatusUnauthorized, "Invalid token", nil)
+			c.Abort()
+			return
+		}
+		issuedAt, _ := claims["iat"].(float64)
+		revoked, err := service.IsRevoked(tokenID, sessionID, uint(userID), time.Unix(int64(issuedAt), 0))
+		if err != nil {
+			response.Error(c, http.StatusInternalServerError, "Failed to check token", nil)
+			c.Abort()
+			return
+		}
+		if revoked {
+			response.Error(c, http.StatusUnauthorized, "Token has been revoked", nil)
+			c.Abort()
+			return
+		}
+
+		// Logout needs these to revoke the token it was called with
+		expiresAt, _ := claims["exp"].(float64)
+		c.Set("tokenID", tokenID)
+		c.Set("sessionID", sessionID)
+		c.Set("tokenExpiresAt", time.Unix(int64(expiresAt), 0))
+
+		c.Next()
+	}
+}
+
+// OptionalMiddleware authenticates the request like Middleware when an
+// Authorization header is sent and lets anonymous requests through otherwise.
+func OptionalMiddleware(jwtService JWTService, service Service, silentRefresh bool) gin.HandlerFunc {
+	required := Middleware(jwtService, service, silentRefresh)
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			c.Next()
+			return
+		}
+		required(c)
+	}
+}
+
+// RequirePermission lets the request through only when the role Middleware
+// put in the context grants perm. It must run after Middleware.
+func RequirePermission(perm entity.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, _ := c.Get("role")
+		if r, ok := role.(entity.Role); !ok || !r.Can(perm) {
+			response.Error(c, http.StatusForbidden, "Forbidden", "missing permission "+string(perm))
+			c.Abort()
+			return
+		}
 		c.Next()
 	}
 }
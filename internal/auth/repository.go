@@ -0,0 +1,181 @@
+package auth
+
+import (
+	"time"
+
+	"post/internal/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repository stores sessions, the refresh tokens that have been issued and
+// the tokens that have been revoked.
+type Repository interface {
+	CreateSession(session *entity.Session, token *entity.RefreshToken) error
+	FindSessions(userID uint) ([]entity.Session, error)
+	TouchSession(id string, now time.Time) (bool, error)
+	RefreshSession(id string, client Client, expiresAt time.Time) (bool, error)
+	RevokeSession(userID uint, id string) (bool, error)
+	RevokeUserSessions(userID uint) error
+	CreateRefreshToken(token *entity.RefreshToken) error
+	FindRefreshToken(id string) (*entity.RefreshToken, error)
+	UseRefreshToken(id string) (bool, error)
+	RevokeToken(token *entity.RevokedToken) error
+	IsTokenRevoked(id string) (bool, error)
+	RevokeUser(revocation *entity.UserRevocation) error
+	FindUserRevocation(userID uint) (*entity.UserRevocation, error)
+	DeleteExpired(now time.Time) (int64, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db}
+}
+
+// CreateSession stores a new session together with its first refresh token,
+// so a failed signin leaves neither behind.
+func (r *repository) CreateSession(session *entity.Session, token *entity.RefreshToken) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(session).Error; err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+}
+
+// FindSessions returns the user's live sessions, most recently used first.
+func (r *repository) FindSessions(userID uint) ([]entity.Session, error) {
+	var sessions []entity.Session
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// live scopes sessions to the one given, if it is neither revoked nor expired.
+func (r *repository) live(id string) *gorm.DB {
+	return r.db.Model(&entity.Session{}).Where("id = ? AND revoked_at IS NULL AND expires_at > ?", id, time.Now())
+}
+
+// TouchSession records that the session was just used and reports whether
+// it is still live.
+func (r *repository) TouchSession(id string, now time.Time) (bool, error) {
+	result := r.live(id).Update("last_seen_at", now)
+	return result.RowsAffected == 1, result.Error
+}
+
+// RefreshSession records where the session was refreshed from and extends
+// it to the new refresh token. It reports whether the session is still live.
+func (r *repository) RefreshSession(id string, client Client, expiresAt time.Time) (bool, error) {
+	result := r.live(id).Updates(map[string]any{
+		"user_agent":   client.UserAgent,
+		"ip":           client.IP,
+		"last_seen_at": time.Now(),
+		"expires_at":   expiresAt,
+	})
+	return result.RowsAffected == 1, result.Error
+}
+
+// RevokeSession revokes one of the user's sessions along with its refresh
+// tokens. It reports false when the user has no such live session.
+func (r *repository) RevokeSession(userID uint, id string) (bool, error) {
+	var revoked bool
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&entity.Session{}).
+			Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+			Update("revoked_at", now)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		revoked = true
+		return tx.Model(&entity.RefreshToken{}).
+			Where("family_id = ? AND revoked_at IS NULL", id).
+			Update("revoked_at", now).Error
+	})
+	return revoked, err
+}
+
+// RevokeUserSessions revokes all of the user's sessions and refresh tokens.
+func (r *repository) RevokeUserSessions(userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Model(&entity.Session{}).
+			Where("user_id = ? AND revoked_at IS NULL", userID).
+			Update("revoked_at", now).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&entity.RefreshToken{}).
+			Where("user_id = ? AND revoked_at IS NULL", userID).
+			Update("revoked_at", now).Error
+	})
+}
+
+func (r *repository) CreateRefreshToken(token *entity.RefreshToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *repository) FindRefreshToken(id string) (*entity.RefreshToken, error) {
+	var token entity.RefreshToken
+	err := r.db.Where("id = ?", id).First(&token).Error
+	return &token, err
+}
+
+// UseRefreshToken marks the token used and reports whether this call did so.
+// It reports false when the token had already been used, so of two requests
+// racing with the same token only one gets to rotate it.
+func (r *repository) UseRefreshToken(id string) (bool, error) {
+	result := r.db.Model(&entity.RefreshToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	return result.RowsAffected == 1, result.Error
+}
+
+// RevokeToken records the revocation, keeping the first one if the token was
+// already revoked.
+func (r *repository) RevokeToken(token *entity.RevokedToken) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(token).Error
+}
+
+func (r *repository) IsTokenRevoked(id string) (bool, error) {
+	var count int64
+	err := r.db.Model(&entity.RevokedToken{}).Where("id = ?", id).Count(&count).Error
+	return count > 0, err
+}
+
+// RevokeUser records the revocation, replacing an earlier one for the user.
+func (r *repository) RevokeUser(revocation *entity.UserRevocation) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"revoked_before", "expires_at"}),
+	}).Create(revocation).Error
+}
+
+func (r *repository) FindUserRevocation(userID uint) (*entity.UserRevocation, error) {
+	var revocation entity.UserRevocation
+	err := r.db.Where("user_id = ?", userID).First(&revocation).Error
+	return &revocation, err
+}
+
+// DeleteExpired removes revocations, refresh tokens and sessions that have
+// expired, as expiry alone rejects their tokens now. It returns how many rows
+// went.
+func (r *repository) DeleteExpired(now time.Time) (int64, error) {
+	var deleted int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		for _, model := range []any{&entity.RevokedToken{}, &entity.UserRevocation{}, &entity.RefreshToken{}, &entity.Session{}} {
+			result := tx.Where("expires_at < ?", now).Delete(model)
+			if result.Error != nil {
+				return result.Error
+			}
+			deleted += result.RowsAffected
+		}
+		return nil
+	})
+	return deleted, err
+}
@@ -0,0 +1,174 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"post/internal/entity"
+	"post/internal/pkg/cache"
+	pkgdb "post/internal/pkg/database"
+)
+
+// revocationCheckTTL is how long a token, session or user found not revoked
+// is trusted before Postgres is asked again. Revocations made by another
+// process take at most this long to reach this one, and it is also how often
+// a session's last-seen time moves. Revocations themselves are cached for
+// good.
+const revocationCheckTTL = 30 * time.Second
+
+// RevocationStore records revoked tokens and sessions in Postgres and keeps
+// the answers to recent checks in an LRU, so most requests never reach the
+// database.
+type RevocationStore interface {
+	// RevokeToken blocks the token with the given jti until it expires.
+	RevokeToken(id string, userID uint, expiresAt time.Time) error
+	// RevokeSession blocks every token of one of the user's sessions. It
+	// reports false when the user has no such live session.
+	RevokeSession(userID uint, sessionID string) (bool, error)
+	// RevokeUser blocks every token and session of the user issued up to now.
+	RevokeUser(userID uint) error
+	IsRevoked(id, sessionID string, userID uint, issuedAt time.Time) (bool, error)
+}
+
+type revocationStore struct {
+	repo  Repository
+	cache cache.Cache
+	// maxAge is the lifetime of the longest lived token, after which a
+	// user revocation has nothing left to block.
+	maxAge time.Duration
+}
+
+func NewRevocationStore(repo Repository, cache cache.Cache, maxAge time.Duration) RevocationStore {
+	return &revocationStore{repo, cache, maxAge}
+}
+
+// revocationCheck is a cached answer. before is the user's cut-off and is
+// zero for tokens and for users without one.
+type revocationCheck struct {
+	revoked   bool
+	before    time.Time
+	checkedAt time.Time
+}
+
+func (s *revocationStore) RevokeToken(id string, userID uint, expiresAt time.Time) error {
+	err := s.repo.RevokeToken(&entity.RevokedToken{ID: id, UserID: userID, ExpiresAt: expiresAt})
+	if err != nil {
+		return err
+	}
+	s.cache.Set(tokenKey(id), revocationCheck{revoked: true})
+	return nil
+}
+
+func (s *revocationStore) RevokeSession(userID uint, sessionID string) (bool, error) {
+	revoked, err := s.repo.RevokeSession(userID, sessionID)
+	if err != nil || !revoked {
+		return false, err
+	}
+	s.cache.Set(sessionKey(sessionID), revocationCheck{revoked: true})
+	return true, nil
+}
+
+func (s *revocationStore) RevokeUser(userID uint) error {
+	now := time.Now()
+	revocation := &entity.UserRevocation{UserID: userID, RevokedBefore: now, ExpiresAt: now.Add(s.maxAge)}
+	if err := s.repo.RevokeUser(revocation); err != nil {
+		return err
+	}
+	s.cache.Set(userKey(userID), revocationCheck{before: now, checkedAt: now})
+	return s.repo.RevokeUserSessions(userID)
+}
+
+// IsRevoked reports whether the token was revoked on its own, with its
+// session or by revoking every token of its user. JWTs carry iat in whole
+// seconds, so a token issued in the same second as a user revocation counts
+// as revoked.
+func (s *revocationStore) IsRevoked(id, sessionID string, userID uint, issuedAt time.Time) (bool, error) {
+	before, err := s.revokedBefore(userID)
+	if err != nil {
+		return false, err
+	}
+	if !before.IsZero() && !issuedAt.After(before) {
+		return true, nil
+	}
+
+	live, err := s.sessionLive(sessionID)
+	if err != nil {
+		return false, err
+	}
+	if !live {
+		return true, nil
+	}
+
+	key := tokenKey(id)
+	if check, ok := s.cached(key); ok {
+		return check.revoked, nil
+	}
+	revoked, err := s.repo.IsTokenRevoked(id)
+	if err != nil {
+		return false, err
+	}
+	s.cache.Set(key, revocationCheck{revoked: revoked, checkedAt: time.Now()})
+	return revoked, nil
+}
+
+// sessionLive reports whether the session is neither revoked nor expired.
+// Asking Postgres also moves the session's last-seen time.
+func (s *revocationStore) sessionLive(sessionID string) (bool, error) {
+	key := sessionKey(sessionID)
+	if check, ok := s.cached(key); ok {
+		return !check.revoked, nil
+	}
+	now := time.Now()
+	live, err := s.repo.TouchSession(sessionID, now)
+	if err != nil {
+		return false, err
+	}
+	s.cache.Set(key, revocationCheck{revoked: !live, checkedAt: now})
+	return live, nil
+}
+
+// revokedBefore returns the user's cut-off, or zero if there is none.
+func (s *revocationStore) revokedBefore(userID uint) (time.Time, error) {
+	key := userKey(userID)
+	if check, ok := s.cached(key); ok {
+		return check.before, nil
+	}
+
+	var before time.Time
+	revocation, err := s.repo.FindUserRevocation(userID)
+	switch {
+	case err == nil:
+		before = revocation.RevokedBefore
+	case !errors.Is(pkgdb.ParseError(err), pkgdb.ErrRecordNotFound):
+		return time.Time{}, err
+	}
+	s.cache.Set(key, revocationCheck{before: before, checkedAt: time.Now()})
+	return before, nil
+}
+
+// cached returns a cached answer that can still be trusted: a revocation, or
+// a recent check.
+func (s *revocationStore) cached(key string) (revocationCheck, bool) {
+	value, ok := s.cache.Get(key)
+	if !ok {
+		return revocationCheck{}, false
+	}
+	check := value.(revocationCheck)
+	if !check.revoked && time.Since(check.checkedAt) > revocationCheckTTL {
+		return revocationCheck{}, false
+	}
+	return check, true
+}
+
+func tokenKey(id string) string {
+	return "revoked:token:" + id
+}
+
+func sessionKey(id string) string {
+	return "revoked:session:" + id
+}
+
+func userKey(userID uint) string {
+	return fmt.Sprintf("revoked:user:%d", userID)
+}
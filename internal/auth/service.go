@@ -2,32 +2,58 @@ package auth
 
 import (
 	"errors"
+	"time"
 
 	"post/internal/entity"
 	pkgdb "post/internal/pkg/database"
 	"post/internal/user"
 
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
+var (
+	ErrEmailTaken          = errors.New("email already registered")
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token was already used, sign in again")
+	ErrSessionNotFound     = errors.New("session not found")
+)
+
 type Service interface {
 	Signup(input SignupInput) (*entity.User, error)
-	Signin(input SigninInput) (string, string, error)
+	Signin(input SigninInput, client Client) (string, string, error)
+	Refresh(refreshToken string, client Client) (string, string, error)
+	RefreshAccessToken(refreshToken string) (string, error)
+	Logout(userID uint, tokenID, sessionID string, expiresAt time.Time) error
+	LogoutAll(userID uint) error
+	GetSessions(userID uint) ([]entity.Session, error)
+	RevokeSession(userID uint, sessionID string) error
+	IsRevoked(tokenID, sessionID string, userID uint, issuedAt time.Time) (bool, error)
+}
+
+// Client is the device a signin or refresh came from.
+type Client struct {
+	UserAgent string
+	IP        string
 }
 
 type service struct {
-	userRepo   user.Repository
-	jwtService JWTService
+	userRepo    user.Repository
+	repo        Repository
+	revocations RevocationStore
+	jwtService  JWTService
 }
 
-func NewService(userRepo user.Repository, jwtService JWTService) Service {
-	return &service{userRepo, jwtService}
+func NewService(userRepo user.Repository, repo Repository, revocations RevocationStore, jwtService JWTService) Service {
+	return &service{userRepo, repo, revocations, jwtService}
 }
 
+// SignupInput has no role: every account starts as a plain user and only an
+// admin can change that.
 type SignupInput struct {
-	Email    string      `json:"email" binding:"required,email"`
-	Password string      `json:"password" binding:"required,min=6"`
-	Role     entity.Role `json:"role"`
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required,min=6"`
 }
 
 type SigninInput struct {
@@ -36,6 +62,10 @@ type SigninInput struct {
 }
 
 func (s *service) Signup(input SignupInput) (*entity.User, error) {
+	if _, err := s.userRepo.FindByEmail(input.Email); err == nil {
+		return nil, ErrEmailTaken
+	}
+
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
 	if err != nil {
 		return nil, err
@@ -44,22 +74,22 @@ func (s *service) Signup(input SignupInput) (*entity.User, error) {
 	user := &entity.User{
 		Email:    input.Email,
 		Password: string(hashedPassword),
-		Role:     input.Role,
-	}
-
-	// Default to user role if not provided or restricted
-	if user.Role == 0 {
-		user.Role = entity.RoleUser
+		Role:     entity.RoleUser,
 	}
 
 	if err := s.userRepo.Create(user); err != nil {
-		return nil, pkgdb.ParseError(err)
+		err = pkgdb.ParseError(err)
+		if errors.Is(err, pkgdb.ErrDuplicateKey) {
+			return nil, ErrEmailTaken
+		}
+		return nil, err
 	}
 
 	return user, nil
 }
 
-func (s *service) Signin(input SigninInput) (string, string, error) {
+// Signin checks the credentials and starts a new session for the client.
+func (s *service) Signin(input SigninInput, client Client) (string, string, error) {
 	user, err := s.userRepo.FindByEmail(input.Email)
 	if err != nil {
 		return "", "", errors.New("invalid email or password")
@@ -69,15 +99,175 @@ func (s *service) Signin(input SigninInput) (string, string, error) {
 		return "", "", errors.New("invalid email or password")
 	}
 
-	token, err := s.jwtService.GenerateToken(user)
+	session := &entity.Session{
+		ID:         uuid.NewString(),
+		UserID:     user.ID,
+		UserAgent:  client.UserAgent,
+		IP:         client.IP,
+		LastSeenAt: time.Now(),
+	}
+	token, refreshToken, record, err := s.sign(user, session.ID)
 	if err != nil {
 		return "", "", err
 	}
+	session.ExpiresAt = record.ExpiresAt
+	if err := s.repo.CreateSession(session, record); err != nil {
+		return "", "", err
+	}
+	return token, refreshToken, nil
+}
 
-	refreshToken, err := s.jwtService.GenerateRefreshToken(user)
+// Refresh trades a refresh token for a new access and refresh token pair and
+// retires the one presented. Presenting a token that was already traded means
+// someone else has a copy, so its whole session is revoked and both holders
+// have to sign in again.
+func (s *service) Refresh(refreshToken string, client Client) (string, string, error) {
+	_, record, err := s.checkRefreshToken(refreshToken)
 	if err != nil {
 		return "", "", err
 	}
 
-	return token, refreshToken, nil
+	used, err := s.repo.UseRefreshToken(record.ID)
+	if err != nil {
+		return "", "", err
+	}
+	if !used {
+		if _, err := s.revocations.RevokeSession(record.UserID, record.FamilyID); err != nil {
+			return "", "", err
+		}
+		return "", "", ErrRefreshTokenReused
+	}
+
+	// Load the user again so a changed role or a deleted account takes
+	// effect on refresh.
+	user, err := s.userRepo.FindByID(record.UserID)
+	if err != nil {
+		return "", "", ErrInvalidRefreshToken
+	}
+	token, newRefreshToken, next, err := s.issue(user, record.FamilyID)
+	if err != nil {
+		return "", "", err
+	}
+	live, err := s.repo.RefreshSession(record.FamilyID, client, next.ExpiresAt)
+	if err != nil {
+		return "", "", err
+	}
+	if !live {
+		return "", "", ErrInvalidRefreshToken
+	}
+	return token, newRefreshToken, nil
+}
+
+// RefreshAccessToken checks a refresh token without using it up and signs a
+// new access token for its session. The user is loaded again, as in Refresh,
+// so a changed role or a deleted account takes effect. It backs the
+// deprecated refresh in Middleware.
+func (s *service) RefreshAccessToken(refreshToken string) (string, error) {
+	_, record, err := s.checkRefreshToken(refreshToken)
+	if err != nil {
+		return "", err
+	}
+	if record.UsedAt != nil {
+		return "", ErrInvalidRefreshToken
+	}
+
+	user, err := s.userRepo.FindByID(record.UserID)
+	if err != nil {
+		return "", ErrInvalidRefreshToken
+	}
+	return s.jwtService.GenerateToken(user, record.FamilyID)
+}
+
+// checkRefreshToken validates a refresh token and loads its record, which
+// must belong to the same user and be neither revoked nor expired.
+func (s *service) checkRefreshToken(refreshToken string) (jwt.MapClaims, *entity.RefreshToken, error) {
+	token, err := s.jwtService.ValidateToken(refreshToken)
+	if err != nil || !token.Valid {
+		return nil, nil, ErrInvalidRefreshToken
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["type"] != "refresh" {
+		return nil, nil, ErrInvalidRefreshToken
+	}
+	id, _ := claims["jti"].(string)
+	userID, _ := claims["user_id"].(float64)
+	if id == "" {
+		return nil, nil, ErrInvalidRefreshToken
+	}
+
+	record, err := s.repo.FindRefreshToken(id)
+	if err != nil {
+		if errors.Is(pkgdb.ParseError(err), pkgdb.ErrRecordNotFound) {
+			return nil, nil, ErrInvalidRefreshToken
+		}
+		return nil, nil, err
+	}
+	if record.UserID != uint(userID) || record.RevokedAt != nil || time.Now().After(record.ExpiresAt) {
+		return nil, nil, ErrInvalidRefreshToken
+	}
+	return claims, record, nil
+}
+
+// Logout revokes the access token it was called with and ends its session.
+func (s *service) Logout(userID uint, tokenID, sessionID string, expiresAt time.Time) error {
+	if err := s.revocations.RevokeToken(tokenID, userID, expiresAt); err != nil {
+		return err
+	}
+	_, err := s.revocations.RevokeSession(userID, sessionID)
+	return err
+}
+
+// LogoutAll revokes every token the user holds, on every device.
+func (s *service) LogoutAll(userID uint) error {
+	return s.revocations.RevokeUser(userID)
+}
+
+// GetSessions lists the devices the user is signed in on.
+func (s *service) GetSessions(userID uint) ([]entity.Session, error) {
+	return s.repo.FindSessions(userID)
+}
+
+// RevokeSession signs the user out on one device.
+func (s *service) RevokeSession(userID uint, sessionID string) error {
+	revoked, err := s.revocations.RevokeSession(userID, sessionID)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+func (s *service) IsRevoked(tokenID, sessionID string, userID uint, issuedAt time.Time) (bool, error) {
+	return s.revocations.IsRevoked(tokenID, sessionID, userID, issuedAt)
+}
+
+// issue signs an access token and a refresh token for the session and
+// records the refresh token.
+func (s *service) issue(user *entity.User, sessionID string) (string, string, *entity.RefreshToken, error) {
+	token, refreshToken, record, err := s.sign(user, sessionID)
+	if err != nil {
+		return "", "", nil, err
+	}
+	if err := s.repo.CreateRefreshToken(record); err != nil {
+		return "", "", nil, err
+	}
+	return token, refreshToken, record, nil
+}
+
+// sign signs an access token and a refresh token for the session. The
+// refresh token's record is returned for the caller to store.
+func (s *service) sign(user *entity.User, sessionID string) (string, string, *entity.RefreshToken, error) {
+	token, err := s.jwtService.GenerateToken(user, sessionID)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	record := &entity.RefreshToken{ID: uuid.NewString(), FamilyID: sessionID, UserID: user.ID}
+	refreshToken, err := s.jwtService.GenerateRefreshToken(user, record)
+	if err != nil {
+		return "", "", nil, err
+	}
+	return token, refreshToken, record, nil
 }
@@ -1,16 +1,28 @@
 package auth_test
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
 	"errors"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"post/internal/auth"
 	"post/internal/entity"
+	"post/internal/pkg/cache"
+	"post/internal/pkg/config"
+	"post/internal/user"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 // MockUserRepository is a mock of user.Repository
@@ -47,6 +59,21 @@ func (m *MockUserRepository) FindAll() ([]entity.User, error) {
 	return args.Get(0).([]entity.User), args.Error(1)
 }
 
+func (m *MockUserRepository) FindAuthors(offset, limit int) ([]user.Author, error) {
+	args := m.Called(offset, limit)
+	return args.Get(0).([]user.Author), args.Error(1)
+}
+
+func (m *MockUserRepository) CountAuthors() (int64, error) {
+	args := m.Called()
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockUserRepository) UpdateRole(id uint, role entity.Role) error {
+	args := m.Called(id, role)
+	return args.Error(0)
+}
+
 func (m *MockUserRepository) Delete(id uint) error {
 	args := m.Called(id)
 	return args.Error(0)
@@ -57,13 +84,13 @@ type MockJWTService struct {
 	mock.Mock
 }
 
-func (m *MockJWTService) GenerateToken(user *entity.User) (string, error) {
-	args := m.Called(user)
+func (m *MockJWTService) GenerateToken(user *entity.User, familyID string) (string, error) {
+	args := m.Called(user, familyID)
 	return args.String(0), args.Error(1)
 }
 
-func (m *MockJWTService) GenerateRefreshToken(user *entity.User) (string, error) {
-	args := m.Called(user)
+func (m *MockJWTService) GenerateRefreshToken(user *entity.User, record *entity.RefreshToken) (string, error) {
+	args := m.Called(user, record)
 	return args.String(0), args.Error(1)
 }
 
@@ -75,10 +102,121 @@ func (m *MockJWTService) ValidateToken(tokenString string) (*jwt.Token, error) {
 	return args.Get(0).(*jwt.Token), args.Error(1)
 }
 
+func (m *MockJWTService) KeySet() auth.KeySet {
+	args := m.Called()
+	return args.Get(0).(auth.KeySet)
+}
+
+// MockRepository is a mock of auth.Repository
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) CreateSession(session *entity.Session, token *entity.RefreshToken) error {
+	args := m.Called(session, token)
+	return args.Error(0)
+}
+
+func (m *MockRepository) FindSessions(userID uint) ([]entity.Session, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]entity.Session), args.Error(1)
+}
+
+func (m *MockRepository) TouchSession(id string, now time.Time) (bool, error) {
+	args := m.Called(id, now)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) RefreshSession(id string, client auth.Client, expiresAt time.Time) (bool, error) {
+	args := m.Called(id, client, expiresAt)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) RevokeSession(userID uint, id string) (bool, error) {
+	args := m.Called(userID, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) RevokeUserSessions(userID uint) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) CreateRefreshToken(token *entity.RefreshToken) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockRepository) FindRefreshToken(id string) (*entity.RefreshToken, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.RefreshToken), args.Error(1)
+}
+
+func (m *MockRepository) UseRefreshToken(id string) (bool, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) RevokeToken(token *entity.RevokedToken) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockRepository) IsTokenRevoked(id string) (bool, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) RevokeUser(revocation *entity.UserRevocation) error {
+	args := m.Called(revocation)
+	return args.Error(0)
+}
+
+func (m *MockRepository) FindUserRevocation(userID uint) (*entity.UserRevocation, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.UserRevocation), args.Error(1)
+}
+
+func (m *MockRepository) DeleteExpired(now time.Time) (int64, error) {
+	args := m.Called(now)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// MockRevocationStore is a mock of auth.RevocationStore
+type MockRevocationStore struct {
+	mock.Mock
+}
+
+func (m *MockRevocationStore) RevokeToken(id string, userID uint, expiresAt time.Time) error {
+	args := m.Called(id, userID, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockRevocationStore) RevokeSession(userID uint, sessionID string) (bool, error) {
+	args := m.Called(userID, sessionID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRevocationStore) RevokeUser(userID uint) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockRevocationStore) IsRevoked(id, sessionID string, userID uint, issuedAt time.Time) (bool, error) {
+	args := m.Called(id, sessionID, userID, issuedAt)
+	return args.Bool(0), args.Error(1)
+}
+
 func TestSignup(t *testing.T) {
 	mockRepo := new(MockUserRepository)
 	mockJWT := new(MockJWTService)
-	service := auth.NewService(mockRepo, mockJWT)
+	service := auth.NewService(mockRepo, new(MockRepository), new(MockRevocationStore), mockJWT)
 
 	t.Run("Success", func(t *testing.T) {
 		input := auth.SignupInput{
@@ -119,7 +257,8 @@ func TestSignup(t *testing.T) {
 func TestSignin(t *testing.T) {
 	mockRepo := new(MockUserRepository)
 	mockJWT := new(MockJWTService)
-	service := auth.NewService(mockRepo, mockJWT)
+	mockTokens := new(MockRepository)
+	service := auth.NewService(mockRepo, mockTokens, new(MockRevocationStore), mockJWT)
 
 	password := "password"
 	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
@@ -137,16 +276,38 @@ func TestSignin(t *testing.T) {
 		}
 
 		mockRepo.On("FindByEmail", input.Email).Return(user, nil)
-		mockJWT.On("GenerateToken", user).Return("mock_token", nil)
-		mockJWT.On("GenerateRefreshToken", user).Return("mock_refresh_token", nil)
+		mockJWT.On("GenerateToken", user, mock.AnythingOfType("string")).Return("mock_token", nil)
+		mockJWT.On("GenerateRefreshToken", user, mock.AnythingOfType("*entity.RefreshToken")).Return("mock_refresh_token", nil)
+		mockTokens.On("CreateSession", mock.MatchedBy(func(session *entity.Session) bool {
+			return session.UserID == 1 && session.IP == "10.0.0.1" && session.UserAgent == "curl"
+		}), mock.MatchedBy(func(r *entity.RefreshToken) bool {
+			return r.UserID == 1 && r.FamilyID != ""
+		})).Return(nil).Once()
 
-		token, refreshToken, err := service.Signin(input)
+		token, refreshToken, err := service.Signin(input, auth.Client{UserAgent: "curl", IP: "10.0.0.1"})
 
 		assert.NoError(t, err)
 		assert.Equal(t, "mock_token", token)
 		assert.Equal(t, "mock_refresh_token", refreshToken)
 		mockRepo.AssertExpectations(t)
 		mockJWT.AssertExpectations(t)
+		mockTokens.AssertExpectations(t)
+	})
+
+	t.Run("SessionFailed", func(t *testing.T) {
+		input := auth.SigninInput{
+			Email:    "test@example.com",
+			Password: "password",
+		}
+
+		mockTokens.On("CreateSession", mock.AnythingOfType("*entity.Session"), mock.AnythingOfType("*entity.RefreshToken")).Return(errors.New("db down")).Once()
+
+		token, refreshToken, err := service.Signin(input, auth.Client{})
+
+		assert.Error(t, err)
+		assert.Equal(t, "", token)
+		assert.Equal(t, "", refreshToken)
+		mockTokens.AssertNotCalled(t, "CreateRefreshToken", mock.Anything)
 	})
 
 	t.Run("InvalidPassword", func(t *testing.T) {
@@ -157,7 +318,7 @@ func TestSignin(t *testing.T) {
 
 		mockRepo.On("FindByEmail", input.Email).Return(user, nil)
 
-		token, refreshToken, err := service.Signin(input)
+		token, refreshToken, err := service.Signin(input, auth.Client{})
 
 		assert.Error(t, err)
 		assert.Equal(t, "", token)
@@ -174,7 +335,7 @@ func TestSignin(t *testing.T) {
 
 		mockRepo.On("FindByEmail", input.Email).Return(nil, errors.New("user not found"))
 
-		token, refreshToken, err := service.Signin(input)
+		token, refreshToken, err := service.Signin(input, auth.Client{})
 
 		assert.Error(t, err)
 		assert.Equal(t, "", token)
@@ -183,3 +344,374 @@ func TestSignin(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 }
+
+func TestRefresh(t *testing.T) {
+	user := &entity.User{ID: 1, Role: entity.RoleEditor}
+	client := auth.Client{UserAgent: "curl", IP: "10.0.0.1"}
+	refreshClaims := jwt.MapClaims{"type": "refresh", "user_id": float64(1), "jti": "token-1", "sid": "session-1"}
+	stored := func() *entity.RefreshToken {
+		return &entity.RefreshToken{ID: "token-1", FamilyID: "session-1", UserID: 1, ExpiresAt: time.Now().Add(time.Hour)}
+	}
+
+	setup := func() (*MockUserRepository, *MockRepository, *MockRevocationStore, *MockJWTService, auth.Service) {
+		mockRepo := new(MockUserRepository)
+		mockTokens := new(MockRepository)
+		mockRevocations := new(MockRevocationStore)
+		mockJWT := new(MockJWTService)
+		mockJWT.On("ValidateToken", "refresh").Return(&jwt.Token{Claims: refreshClaims, Valid: true}, nil)
+		return mockRepo, mockTokens, mockRevocations, mockJWT, auth.NewService(mockRepo, mockTokens, mockRevocations, mockJWT)
+	}
+	issues := func(mockRepo *MockUserRepository, mockTokens *MockRepository, mockJWT *MockJWTService) {
+		mockTokens.On("FindRefreshToken", "token-1").Return(stored(), nil)
+		mockTokens.On("UseRefreshToken", "token-1").Return(true, nil)
+		mockRepo.On("FindByID", uint(1)).Return(user, nil)
+		mockJWT.On("GenerateToken", user, "session-1").Return("new_token", nil)
+		mockJWT.On("GenerateRefreshToken", user, mock.MatchedBy(func(r *entity.RefreshToken) bool {
+			return r.FamilyID == "session-1" && r.ID != "token-1"
+		})).Return("new_refresh", nil)
+		mockTokens.On("CreateRefreshToken", mock.AnythingOfType("*entity.RefreshToken")).Return(nil)
+	}
+
+	t.Run("Rotates", func(t *testing.T) {
+		mockRepo, mockTokens, _, mockJWT, service := setup()
+		issues(mockRepo, mockTokens, mockJWT)
+		mockTokens.On("RefreshSession", "session-1", client, mock.AnythingOfType("time.Time")).Return(true, nil)
+
+		token, refreshToken, err := service.Refresh("refresh", client)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "new_token", token)
+		assert.Equal(t, "new_refresh", refreshToken)
+		mockTokens.AssertExpectations(t)
+		mockJWT.AssertExpectations(t)
+	})
+
+	t.Run("SessionRevoked", func(t *testing.T) {
+		mockRepo, mockTokens, _, mockJWT, service := setup()
+		issues(mockRepo, mockTokens, mockJWT)
+		mockTokens.On("RefreshSession", "session-1", client, mock.AnythingOfType("time.Time")).Return(false, nil)
+
+		_, _, err := service.Refresh("refresh", client)
+
+		assert.ErrorIs(t, err, auth.ErrInvalidRefreshToken)
+	})
+
+	t.Run("ReuseRevokesSession", func(t *testing.T) {
+		_, mockTokens, mockRevocations, mockJWT, service := setup()
+		mockTokens.On("FindRefreshToken", "token-1").Return(stored(), nil)
+		mockTokens.On("UseRefreshToken", "token-1").Return(false, nil)
+		mockRevocations.On("RevokeSession", uint(1), "session-1").Return(true, nil)
+
+		_, _, err := service.Refresh("refresh", client)
+
+		assert.ErrorIs(t, err, auth.ErrRefreshTokenReused)
+		mockRevocations.AssertExpectations(t)
+		mockJWT.AssertNotCalled(t, "GenerateToken", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Revoked", func(t *testing.T) {
+		_, mockTokens, _, _, service := setup()
+		revoked := stored()
+		now := time.Now()
+		revoked.RevokedAt = &now
+		mockTokens.On("FindRefreshToken", "token-1").Return(revoked, nil)
+
+		_, _, err := service.Refresh("refresh", client)
+
+		assert.ErrorIs(t, err, auth.ErrInvalidRefreshToken)
+		mockTokens.AssertNotCalled(t, "UseRefreshToken", mock.Anything)
+	})
+
+	t.Run("AccessToken", func(t *testing.T) {
+		mockJWT := new(MockJWTService)
+		service := auth.NewService(new(MockUserRepository), new(MockRepository), new(MockRevocationStore), mockJWT)
+		mockJWT.On("ValidateToken", "access").Return(&jwt.Token{Claims: jwt.MapClaims{"type": "access", "user_id": float64(1)}, Valid: true}, nil)
+
+		_, _, err := service.Refresh("access", client)
+
+		assert.ErrorIs(t, err, auth.ErrInvalidRefreshToken)
+	})
+
+	t.Run("VerifyRejectsUsed", func(t *testing.T) {
+		_, mockTokens, _, _, service := setup()
+		used := stored()
+		now := time.Now()
+		used.UsedAt = &now
+		mockTokens.On("FindRefreshToken", "token-1").Return(used, nil)
+
+		_, err := service.RefreshAccessToken("refresh")
+
+		assert.ErrorIs(t, err, auth.ErrInvalidRefreshToken)
+	})
+
+	t.Run("VerifyReloadsUser", func(t *testing.T) {
+		mockRepo, mockTokens, _, mockJWT, service := setup()
+		demoted := &entity.User{ID: 1, Role: entity.RoleUser}
+		mockTokens.On("FindRefreshToken", "token-1").Return(stored(), nil)
+		mockRepo.On("FindByID", uint(1)).Return(demoted, nil)
+		mockJWT.On("GenerateToken", demoted, "session-1").Return("new_token", nil)
+
+		token, err := service.RefreshAccessToken("refresh")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "new_token", token)
+		mockTokens.AssertNotCalled(t, "UseRefreshToken", mock.Anything)
+		mockJWT.AssertExpectations(t)
+	})
+
+	t.Run("VerifyRejectsDeletedUser", func(t *testing.T) {
+		mockRepo, mockTokens, _, mockJWT, service := setup()
+		mockTokens.On("FindRefreshToken", "token-1").Return(stored(), nil)
+		mockRepo.On("FindByID", uint(1)).Return(nil, errors.New("record not found"))
+
+		_, err := service.RefreshAccessToken("refresh")
+
+		assert.ErrorIs(t, err, auth.ErrInvalidRefreshToken)
+		mockJWT.AssertNotCalled(t, "GenerateToken", mock.Anything, mock.Anything)
+	})
+}
+
+func TestLogout(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour)
+
+	t.Run("RevokesTokenAndSession", func(t *testing.T) {
+		mockRevocations := new(MockRevocationStore)
+		service := auth.NewService(new(MockUserRepository), new(MockRepository), mockRevocations, new(MockJWTService))
+		mockRevocations.On("RevokeToken", "token-1", uint(1), expiresAt).Return(nil)
+		mockRevocations.On("RevokeSession", uint(1), "session-1").Return(true, nil)
+
+		err := service.Logout(1, "token-1", "session-1", expiresAt)
+
+		assert.NoError(t, err)
+		mockRevocations.AssertExpectations(t)
+	})
+
+	t.Run("All", func(t *testing.T) {
+		mockRevocations := new(MockRevocationStore)
+		service := auth.NewService(new(MockUserRepository), new(MockRepository), mockRevocations, new(MockJWTService))
+		mockRevocations.On("RevokeUser", uint(1)).Return(nil)
+
+		err := service.LogoutAll(1)
+
+		assert.NoError(t, err)
+		mockRevocations.AssertExpectations(t)
+	})
+}
+
+func TestRevokeSession(t *testing.T) {
+	mockRevocations := new(MockRevocationStore)
+	service := auth.NewService(new(MockUserRepository), new(MockRepository), mockRevocations, new(MockJWTService))
+
+	t.Run("Success", func(t *testing.T) {
+		mockRevocations.On("RevokeSession", uint(1), "session-1").Return(true, nil)
+
+		assert.NoError(t, service.RevokeSession(1, "session-1"))
+	})
+
+	t.Run("OtherUsersSession", func(t *testing.T) {
+		mockRevocations.On("RevokeSession", uint(2), "session-1").Return(false, nil)
+
+		assert.ErrorIs(t, service.RevokeSession(2, "session-1"), auth.ErrSessionNotFound)
+	})
+}
+
+func TestRevocationStore(t *testing.T) {
+	setup := func() (*MockRepository, auth.RevocationStore) {
+		mockTokens := new(MockRepository)
+		lru, _ := cache.NewLRUCache(10)
+		return mockTokens, auth.NewRevocationStore(mockTokens, lru, time.Hour)
+	}
+	issuedAt := time.Now().Add(-time.Minute)
+
+	t.Run("NotRevokedIsCached", func(t *testing.T) {
+		mockTokens, store := setup()
+		mockTokens.On("FindUserRevocation", uint(1)).Return(nil, gorm.ErrRecordNotFound).Once()
+		mockTokens.On("TouchSession", "session-1", mock.AnythingOfType("time.Time")).Return(true, nil).Once()
+		mockTokens.On("IsTokenRevoked", "token-1").Return(false, nil).Once()
+
+		for range 2 {
+			revoked, err := store.IsRevoked("token-1", "session-1", 1, issuedAt)
+
+			assert.NoError(t, err)
+			assert.False(t, revoked)
+		}
+		mockTokens.AssertExpectations(t)
+	})
+
+	t.Run("RevokeToken", func(t *testing.T) {
+		mockTokens, store := setup()
+		mockTokens.On("RevokeToken", mock.AnythingOfType("*entity.RevokedToken")).Return(nil)
+		mockTokens.On("FindUserRevocation", uint(1)).Return(nil, gorm.ErrRecordNotFound)
+		mockTokens.On("TouchSession", "session-1", mock.AnythingOfType("time.Time")).Return(true, nil)
+
+		assert.NoError(t, store.RevokeToken("token-1", 1, time.Now().Add(time.Hour)))
+		revoked, err := store.IsRevoked("token-1", "session-1", 1, issuedAt)
+
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+		mockTokens.AssertNotCalled(t, "IsTokenRevoked", "token-1")
+	})
+
+	t.Run("RevokeSession", func(t *testing.T) {
+		mockTokens, store := setup()
+		mockTokens.On("RevokeSession", uint(1), "session-1").Return(true, nil)
+		mockTokens.On("FindUserRevocation", uint(1)).Return(nil, gorm.ErrRecordNotFound)
+
+		revoked, err := store.RevokeSession(1, "session-1")
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+
+		revoked, err = store.IsRevoked("token-1", "session-1", 1, issuedAt)
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+		mockTokens.AssertNotCalled(t, "TouchSession", "session-1", mock.Anything)
+	})
+
+	t.Run("ExpiredSession", func(t *testing.T) {
+		mockTokens, store := setup()
+		mockTokens.On("FindUserRevocation", uint(1)).Return(nil, gorm.ErrRecordNotFound)
+		mockTokens.On("TouchSession", "session-1", mock.AnythingOfType("time.Time")).Return(false, nil)
+
+		revoked, err := store.IsRevoked("token-1", "session-1", 1, issuedAt)
+
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+	})
+
+	t.Run("RevokeUser", func(t *testing.T) {
+		mockTokens, store := setup()
+		mockTokens.On("RevokeUser", mock.MatchedBy(func(r *entity.UserRevocation) bool {
+			return r.UserID == 1 && r.ExpiresAt.Sub(r.RevokedBefore) == time.Hour
+		})).Return(nil)
+		mockTokens.On("RevokeUserSessions", uint(1)).Return(nil)
+		mockTokens.On("TouchSession", "session-2", mock.AnythingOfType("time.Time")).Return(true, nil)
+		mockTokens.On("IsTokenRevoked", "token-2").Return(false, nil)
+
+		assert.NoError(t, store.RevokeUser(1))
+
+		revoked, err := store.IsRevoked("token-1", "session-1", 1, issuedAt)
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+
+		revoked, err = store.IsRevoked("token-2", "session-2", 1, time.Now().Add(time.Minute))
+		assert.NoError(t, err)
+		assert.False(t, revoked)
+		mockTokens.AssertExpectations(t)
+	})
+}
+
+func TestJWTService(t *testing.T) {
+	dir := t.TempDir()
+	writeKey := func(name string, key any) string {
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		assert.NoError(t, err)
+		path := filepath.Join(dir, name+".pem")
+		assert.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+		return path
+	}
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	_, otherEdKey, _ := ed25519.GenerateKey(rand.Reader)
+	rsaPath := writeKey("rsa", rsaKey)
+	edPath := writeKey("ed", edKey)
+	otherEdPath := writeKey("ed-other", otherEdKey)
+
+	newService := func(algorithm string, keys ...config.KeyConfig) (auth.JWTService, error) {
+		return auth.NewJWTService(&config.Config{JWT: config.JWTConfig{
+			Algorithm:      algorithm,
+			Secret:         "secret",
+			Keys:           keys,
+			KeyGracePeriod: 1,
+			Expiry:         1,
+			RefreshExpiry:  1,
+		}})
+	}
+	user := &entity.User{ID: 1, Role: entity.RoleUser}
+
+	t.Run("HS256", func(t *testing.T) {
+		service, err := newService("HS256")
+		assert.NoError(t, err)
+
+		token, err := service.GenerateToken(user, "session-1")
+		assert.NoError(t, err)
+		parsed, err := service.ValidateToken(token)
+		assert.NoError(t, err)
+		assert.Equal(t, "HS256", parsed.Method.Alg())
+		assert.Empty(t, service.KeySet().Keys)
+	})
+
+	for _, tc := range []struct {
+		algorithm, kty, path string
+	}{
+		{"RS256", "RSA", rsaPath},
+		{"EdDSA", "OKP", edPath},
+	} {
+		t.Run(tc.algorithm, func(t *testing.T) {
+			service, err := newService(tc.algorithm, config.KeyConfig{ID: "key-1", Path: tc.path})
+			assert.NoError(t, err)
+
+			token, err := service.GenerateToken(user, "session-1")
+			assert.NoError(t, err)
+			parsed, err := service.ValidateToken(token)
+			assert.NoError(t, err)
+			assert.Equal(t, "key-1", parsed.Header["kid"])
+
+			keys := service.KeySet().Keys
+			assert.Len(t, keys, 1)
+			assert.Equal(t, tc.kty, keys[0].KeyType)
+			assert.Equal(t, tc.algorithm, keys[0].Algorithm)
+		})
+	}
+
+	t.Run("WrongKeyType", func(t *testing.T) {
+		_, err := newService("RS256", config.KeyConfig{ID: "key-1", Path: edPath})
+		assert.Error(t, err)
+	})
+
+	t.Run("RejectsOtherAlgorithms", func(t *testing.T) {
+		hs, _ := newService("HS256")
+		ed, _ := newService("EdDSA", config.KeyConfig{ID: "key-1", Path: edPath})
+
+		token, _ := hs.GenerateToken(user, "session-1")
+		_, err := ed.ValidateToken(token)
+		assert.Error(t, err)
+	})
+
+	t.Run("Rotation", func(t *testing.T) {
+		now := time.Now()
+		old := config.KeyConfig{ID: "old", Path: edPath}
+		next := config.KeyConfig{ID: "new", Path: otherEdPath, NotBefore: now.Add(time.Hour)}
+
+		before, _ := newService("EdDSA", old, next)
+		oldToken, _ := before.GenerateToken(user, "session-1")
+		parsed, _ := before.ValidateToken(oldToken)
+		assert.Equal(t, "old", parsed.Header["kid"])
+		// The upcoming key is published before it signs anything
+		assert.Len(t, before.KeySet().Keys, 2)
+
+		// The new key signs, and the old one still verifies within the grace period
+		next.NotBefore = now.Add(-time.Minute)
+		during, _ := newService("EdDSA", old, next)
+		newToken, _ := during.GenerateToken(user, "session-1")
+		parsed, err := during.ValidateToken(newToken)
+		assert.NoError(t, err)
+		assert.Equal(t, "new", parsed.Header["kid"])
+		_, err = during.ValidateToken(oldToken)
+		assert.NoError(t, err)
+
+		// Once the grace period is over the old key is gone
+		next.NotBefore = now.Add(-2 * time.Hour)
+		after, _ := newService("EdDSA", old, next)
+		_, err = after.ValidateToken(oldToken)
+		assert.Error(t, err)
+		assert.Len(t, after.KeySet().Keys, 1)
+	})
+
+	t.Run("NoActiveKey", func(t *testing.T) {
+		service, err := newService("EdDSA", config.KeyConfig{ID: "key-1", Path: edPath, NotBefore: time.Now().Add(time.Hour)})
+		assert.NoError(t, err)
+
+		_, err = service.GenerateToken(user, "session-1")
+		assert.ErrorIs(t, err, auth.ErrNoSigningKey)
+	})
+}
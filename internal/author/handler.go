@@ -0,0 +1,92 @@
+package author
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"post/internal/entity"
+	pkgdb "post/internal/pkg/database"
+	"post/internal/pkg/response"
+	"post/internal/post"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service}
+}
+
+// authoredPost is a post on its author's page. The author is already in the
+// page header, so the embedded user is left out.
+type authoredPost struct {
+	entity.Post
+	User *struct{} `json:"user,omitempty"`
+}
+
+type pageResponse struct {
+	Author Author         `json:"author"`
+	Posts  []authoredPost `json:"posts"`
+}
+
+func (h *Handler) GetAuthorPosts(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid ID", err.Error())
+		return
+	}
+
+	var query post.AuthorQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid query", err)
+		return
+	}
+
+	page, err := h.service.GetByID(uint(id), post.ViewerFrom(c), query)
+	if err != nil {
+		writeError(c, err, "Failed to fetch author")
+		return
+	}
+	writePage(c, page)
+}
+
+func (h *Handler) GetAuthorPostsByHandle(c *gin.Context) {
+	var query post.AuthorQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid query", err)
+		return
+	}
+
+	page, err := h.service.GetByHandle(c.Param("handle"), post.ViewerFrom(c), query)
+	if err != nil {
+		writeError(c, err, "Failed to fetch author")
+		return
+	}
+	writePage(c, page)
+}
+
+func writePage(c *gin.Context, page *Page) {
+	posts := make([]authoredPost, len(page.Posts))
+	for i, p := range page.Posts {
+		posts[i] = authoredPost{Post: p}
+	}
+	response.Paginated(c, http.StatusOK, "Author retrieved", pageResponse{Author: page.Author, Posts: posts}, response.Pagination{
+		Total:   &page.Total,
+		HasMore: page.HasMore,
+		Page:    page.Page,
+		PerPage: page.PerPage,
+	})
+}
+
+func writeError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, pkgdb.ErrRecordNotFound):
+		response.Error(c, http.StatusNotFound, "Author not found", nil)
+	default:
+		response.Error(c, http.StatusInternalServerError, message, err.Error())
+	}
+}
@@ -0,0 +1,114 @@
+package author
+
+import (
+	"time"
+
+	"post/internal/entity"
+	pkgdb "post/internal/pkg/database"
+	"post/internal/post"
+	"post/internal/profile"
+	"post/internal/user"
+)
+
+type Service interface {
+	GetByID(id uint, viewer post.Viewer, query post.AuthorQuery) (*Page, error)
+	GetByHandle(handle string, viewer post.Viewer, query post.AuthorQuery) (*Page, error)
+}
+
+type service struct {
+	users    user.Service
+	profiles profile.Service
+	posts    post.Service
+}
+
+func NewService(users user.Service, profiles profile.Service, posts post.Service) Service {
+	return &service{users, profiles, posts}
+}
+
+// Author is the header of an author page. It only carries what the author
+// shows publicly, never their email or role.
+type Author struct {
+	ID             uint      `json:"id"`
+	Handle         *string   `json:"handle"`
+	Name           string    `json:"name"`
+	Bio            string    `json:"bio"`
+	AvatarURL      string    `json:"avatar_url"`
+	Website        string    `json:"website"`
+	Location       string    `json:"location"`
+	Pronouns       string    `json:"pronouns"`
+	SocialLinks    []string  `json:"social_links"`
+	FollowersCount *int64    `json:"followers_count,omitempty"`
+	FollowingCount *int64    `json:"following_count,omitempty"`
+	JoinedAt       time.Time `json:"joined_at"`
+}
+
+// Page is an author with one page of their published posts.
+type Page struct {
+	Author  Author
+	Posts   []entity.Post
+	Total   int64
+	HasMore bool
+	Page    int
+	PerPage int
+}
+
+func (s *service) GetByID(id uint, viewer post.Viewer, query post.AuthorQuery) (*Page, error) {
+	u, err := s.users.GetByID(id)
+	if err != nil {
+		return nil, pkgdb.ParseError(err)
+	}
+	p, err := s.profiles.GetByUserID(id)
+	if err != nil {
+		if err = pkgdb.ParseError(err); err != pkgdb.ErrRecordNotFound {
+			return nil, err
+		}
+		p = &entity.Profile{}
+	}
+	return s.page(u, p, viewer, query)
+}
+
+// GetByHandle finds the author by their profile handle.
+func (s *service) GetByHandle(handle string, viewer post.Viewer, query post.AuthorQuery) (*Page, error) {
+	p, err := s.profiles.GetByHandle(handle)
+	if err != nil {
+		return nil, err
+	}
+	u, err := s.users.GetByID(p.UserID)
+	if err != nil {
+		return nil, pkgdb.ParseError(err)
+	}
+	return s.page(u, p, viewer, query)
+}
+
+func (s *service) page(u *entity.User, p *entity.Profile, viewer post.Viewer, query post.AuthorQuery) (*Page, error) {
+	posts, err := s.posts.GetByUserID(u.ID, viewer, query)
+	if err != nil {
+		return nil, err
+	}
+
+	socialLinks := p.SocialLinks
+	if socialLinks == nil {
+		socialLinks = []string{}
+	}
+	return &Page{
+		Author: Author{
+			ID:             u.ID,
+			Handle:         p.Handle,
+			Name:           p.Name,
+			Bio:            p.Bio,
+			AvatarURL:      p.AvatarURL,
+			Website:        p.Website,
+			Location:       p.Location,
+			Pronouns:       p.Pronouns,
+			SocialLinks:    socialLinks,
+			FollowersCount: u.FollowersCount,
+			FollowingCount: u.FollowingCount,
+			JoinedAt:       u.CreatedAt,
+		},
+		Posts:   posts.Posts,
+		Total:   posts.Total,
+		HasMore: posts.HasMore,
+		Page:    posts.Page,
+		PerPage: posts.Limit,
+	}, nil
+}
@@ -0,0 +1,155 @@
+package author_test
+
+import (
+	"testing"
+	"time"
+
+	"post/internal/author"
+	"post/internal/entity"
+	pkgdb "post/internal/pkg/database"
+	"post/internal/post"
+	"post/internal/profile"
+	"post/internal/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// MockUserService mocks the part of user.Service authors rely on.
+type MockUserService struct {
+	mock.Mock
+	user.Service
+}
+
+func (m *MockUserService) GetByID(id uint) (*entity.User, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.User), args.Error(1)
+}
+
+// MockProfileService mocks the part of profile.Service authors rely on.
+type MockProfileService struct {
+	mock.Mock
+	profile.Service
+}
+
+func (m *MockProfileService) GetByUserID(userID uint) (*entity.Profile, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Profile), args.Error(1)
+}
+
+func (m *MockProfileService) GetByHandle(handle string) (*entity.Profile, error) {
+	args := m.Called(handle)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Profile), args.Error(1)
+}
+
+// MockPostService mocks the part of post.Service authors rely on.
+type MockPostService struct {
+	mock.Mock
+	post.Service
+}
+
+func (m *MockPostService) GetByUserID(userID uint, viewer post.Viewer, query post.AuthorQuery) (*post.PostPage, error) {
+	args := m.Called(userID, viewer, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*post.PostPage), args.Error(1)
+}
+
+func TestGetByID(t *testing.T) {
+	joined := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	followers := int64(4)
+
+	t.Run("Success", func(t *testing.T) {
+		users, profiles, posts := new(MockUserService), new(MockProfileService), new(MockPostService)
+		service := author.NewService(users, profiles, posts)
+		handle := "jane"
+		query := post.AuthorQuery{Page: 2, PerPage: 1}
+
+		users.On("GetByID", uint(3)).Return(&entity.User{ID: 3, Email: "jane@example.com", CreatedAt: joined, FollowersCount: &followers}, nil)
+		profiles.On("GetByUserID", uint(3)).Return(&entity.Profile{UserID: 3, Handle: &handle, Name: "Jane"}, nil)
+		posts.On("GetByUserID", uint(3), post.Viewer{}, query).Return(&post.PostPage{
+			Posts: []entity.Post{{ID: 9, UserID: 3}}, Total: 2, Page: 2, Limit: 1,
+		}, nil)
+
+		result, err := service.GetByID(3, post.Viewer{}, query)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "jane", *result.Author.Handle)
+		assert.Equal(t, "Jane", result.Author.Name)
+		assert.Equal(t, joined, result.Author.JoinedAt)
+		assert.Equal(t, &followers, result.Author.FollowersCount)
+		assert.Len(t, result.Posts, 1)
+		assert.Equal(t, int64(2), result.Total)
+		assert.Equal(t, 1, result.PerPage)
+	})
+
+	t.Run("Without Profile", func(t *testing.T) {
+		users, profiles, posts := new(MockUserService), new(MockProfileService), new(MockPostService)
+		service := author.NewService(users, profiles, posts)
+
+		users.On("GetByID", uint(3)).Return(&entity.User{ID: 3}, nil)
+		profiles.On("GetByUserID", uint(3)).Return(nil, gorm.ErrRecordNotFound)
+		posts.On("GetByUserID", uint(3), post.Viewer{}, post.AuthorQuery{}).Return(&post.PostPage{}, nil)
+
+		result, err := service.GetByID(3, post.Viewer{}, post.AuthorQuery{})
+
+		assert.NoError(t, err)
+		assert.Nil(t, result.Author.Handle)
+		assert.Empty(t, result.Author.Name)
+		assert.NotNil(t, result.Author.SocialLinks)
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		users, profiles, posts := new(MockUserService), new(MockProfileService), new(MockPostService)
+		service := author.NewService(users, profiles, posts)
+
+		users.On("GetByID", uint(3)).Return(nil, gorm.ErrRecordNotFound)
+
+		result, err := service.GetByID(3, post.Viewer{}, post.AuthorQuery{})
+
+		assert.ErrorIs(t, err, pkgdb.ErrRecordNotFound)
+		assert.Nil(t, result)
+		posts.AssertNotCalled(t, "GetByUserID", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestGetByHandle(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		users, profiles, posts := new(MockUserService), new(MockProfileService), new(MockPostService)
+		service := author.NewService(users, profiles, posts)
+		viewer := post.Viewer{UserID: 5, Role: entity.RoleUser}
+
+		profiles.On("GetByHandle", "@jane").Return(&entity.Profile{UserID: 3, Name: "Jane"}, nil)
+		users.On("GetByID", uint(3)).Return(&entity.User{ID: 3}, nil)
+		posts.On("GetByUserID", uint(3), viewer, post.AuthorQuery{}).Return(&post.PostPage{}, nil)
+
+		result, err := service.GetByHandle("@jane", viewer, post.AuthorQuery{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(3), result.Author.ID)
+		posts.AssertExpectations(t)
+	})
+
+	t.Run("Unknown Handle", func(t *testing.T) {
+		users, profiles, posts := new(MockUserService), new(MockProfileService), new(MockPostService)
+		service := author.NewService(users, profiles, posts)
+
+		profiles.On("GetByHandle", "nobody").Return(nil, pkgdb.ErrRecordNotFound)
+
+		_, err := service.GetByHandle("nobody", post.Viewer{}, post.AuthorQuery{})
+
+		assert.ErrorIs(t, err, pkgdb.ErrRecordNotFound)
+		users.AssertNotCalled(t, "GetByID", mock.Anything)
+	})
+}
@@ -0,0 +1,79 @@
+package bookmark
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	pkgdb "post/internal/pkg/database"
+	"post/internal/pkg/response"
+	"post/internal/post"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service}
+}
+
+func (h *Handler) AddBookmark(c *gin.Context) {
+	postID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid ID", err.Error())
+		return
+	}
+
+	bookmark, err := h.service.Add(uint(postID), post.ViewerFrom(c))
+	if err != nil {
+		if errors.Is(err, pkgdb.ErrRecordNotFound) {
+			response.Error(c, http.StatusNotFound, "Post not found", nil)
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to bookmark post", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Post bookmarked", bookmark)
+}
+
+func (h *Handler) RemoveBookmark(c *gin.Context) {
+	postID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid ID", err.Error())
+		return
+	}
+
+	if err := h.service.Remove(uint(postID), post.ViewerFrom(c)); err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to remove bookmark", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Bookmark removed", nil)
+}
+
+// GetMyBookmarks lists the caller's bookmarked posts, like GetProfile it
+// relies on the user set by auth.Middleware.
+func (h *Handler) GetMyBookmarks(c *gin.Context) {
+	var query ListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid query", err)
+		return
+	}
+
+	page, err := h.service.List(post.ViewerFrom(c), query)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to fetch bookmarks", err.Error())
+		return
+	}
+
+	response.Paginated(c, http.StatusOK, "Bookmarks retrieved", post.NewViews(page.Posts, post.ViewerFrom(c)), response.Pagination{
+		Total:   &page.Total,
+		HasMore: page.HasMore,
+		Page:    page.Page,
+		PerPage: page.PerPage,
+	})
+}
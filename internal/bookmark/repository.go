@@ -0,0 +1,66 @@
+package bookmark
+
+import (
+	"post/internal/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository interface {
+	Create(bookmark *entity.Bookmark) error
+	Delete(userID, postID uint) error
+	FindPosts(userID uint, allStatuses bool, offset, limit int) ([]entity.Post, error)
+	CountPosts(userID uint, allStatuses bool) (int64, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db}
+}
+
+// Create stores the bookmark, keeping the original one if the post was
+// already bookmarked.
+func (r *repository) Create(bookmark *entity.Bookmark) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(bookmark).Error
+}
+
+func (r *repository) Delete(userID, postID uint) error {
+	return r.db.Where("user_id = ? AND post_id = ?", userID, postID).Delete(&entity.Bookmark{}).Error
+}
+
+// bookmarked scopes posts to the user's bookmarks. Going through the posts
+// model drops soft-deleted posts, and the users join drops posts of deleted
+// authors, so their bookmarks are hidden but kept. Posts that are no longer
+// published stay visible only to their author, or to admins when allStatuses
+// is set.
+func (r *repository) bookmarked(userID uint, allStatuses bool) *gorm.DB {
+	query := r.db.Model(&entity.Post{}).
+		Joins("JOIN bookmarks ON bookmarks.post_id = posts.id AND bookmarks.user_id = ?", userID).
+		Joins("JOIN users ON posts.user_id = users.id").
+		Where("users.deleted_at IS NULL")
+	if !allStatuses {
+		query = query.Where("(posts.status = ? OR posts.user_id = ?)", entity.PostStatusPublished, userID)
+	}
+	return query
+}
+
+// FindPosts returns bookmarked posts, most recently bookmarked first.
+func (r *repository) FindPosts(userID uint, allStatuses bool, offset, limit int) ([]entity.Post, error) {
+	var posts []entity.Post
+	err := r.bookmarked(userID, allStatuses).
+		Preload("User.Profile").Preload("Tags").
+		Order("bookmarks.created_at DESC, bookmarks.post_id DESC").
+		Offset(offset).Limit(limit).
+		Find(&posts).Error
+	return posts, err
+}
+
+func (r *repository) CountPosts(userID uint, allStatuses bool) (int64, error) {
+	var total int64
+	err := r.bookmarked(userID, allStatuses).Count(&total).Error
+	return total, err
+}
@@ -0,0 +1,79 @@
+package bookmark
+
+import (
+	"post/internal/entity"
+	"post/internal/pkg/response"
+	"post/internal/post"
+)
+
+type Service interface {
+	Add(postID uint, viewer post.Viewer) (*entity.Bookmark, error)
+	Remove(postID uint, viewer post.Viewer) error
+	List(viewer post.Viewer, query ListQuery) (*Page, error)
+}
+
+type service struct {
+	repo  Repository
+	posts post.Service
+}
+
+func NewService(repo Repository, posts post.Service) Service {
+	return &service{repo, posts}
+}
+
+type ListQuery struct {
+	Page    int `form:"page" binding:"omitempty,min=1"`
+	PerPage int `form:"per_page" binding:"omitempty,min=1,max=100"`
+}
+
+// Page is one page of bookmarked posts.
+type Page struct {
+	Posts   []entity.Post
+	Total   int64
+	HasMore bool
+	Page    int
+	PerPage int
+}
+
+// Add bookmarks a post the viewer can see. Bookmarking it again keeps the
+// original bookmark.
+func (s *service) Add(postID uint, viewer post.Viewer) (*entity.Bookmark, error) {
+	if _, err := s.posts.GetByID(postID, viewer); err != nil {
+		return nil, err
+	}
+
+	bookmark := &entity.Bookmark{UserID: viewer.UserID, PostID: postID}
+	if err := s.repo.Create(bookmark); err != nil {
+		return nil, err
+	}
+	return bookmark, nil
+}
+
+// Remove deletes the bookmark if there is one.
+func (s *service) Remove(postID uint, viewer post.Viewer) error {
+	return s.repo.Delete(viewer.UserID, postID)
+}
+
+func (s *service) List(viewer post.Viewer, query ListQuery) (*Page, error) {
+	page, perPage, offset := response.PageBounds(query.Page, query.PerPage)
+
+	posts, err := s.repo.FindPosts(viewer.UserID, viewer.Can(entity.PermPostsReadAny), offset, perPage)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.posts.AttachReactions(viewer, posts); err != nil {
+		return nil, err
+	}
+	total, err := s.repo.CountPosts(viewer.UserID, viewer.Can(entity.PermPostsReadAny))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Page{
+		Posts:   posts,
+		Total:   total,
+		HasMore: int64(offset+len(posts)) < total,
+		Page:    page,
+		PerPage: perPage,
+	}, nil
+}
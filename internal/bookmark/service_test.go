@@ -0,0 +1,134 @@
+package bookmark_test
+
+import (
+	"testing"
+
+	"post/internal/bookmark"
+	"post/internal/entity"
+	pkgdb "post/internal/pkg/database"
+	"post/internal/post"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockRepository is a mock of bookmark.Repository
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) Create(b *entity.Bookmark) error {
+	args := m.Called(b)
+	return args.Error(0)
+}
+
+func (m *MockRepository) Delete(userID, postID uint) error {
+	args := m.Called(userID, postID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) FindPosts(userID uint, allStatuses bool, offset, limit int) ([]entity.Post, error) {
+	args := m.Called(userID, allStatuses, offset, limit)
+	return args.Get(0).([]entity.Post), args.Error(1)
+}
+
+func (m *MockRepository) CountPosts(userID uint, allStatuses bool) (int64, error) {
+	args := m.Called(userID, allStatuses)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// MockPostService mocks the part of post.Service bookmarks rely on. Calling
+// any other method panics.
+type MockPostService struct {
+	mock.Mock
+	post.Service
+}
+
+func (m *MockPostService) GetByID(id uint, viewer post.Viewer) (*entity.Post, error) {
+	args := m.Called(id, viewer)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Post), args.Error(1)
+}
+
+func (m *MockPostService) AttachReactions(viewer post.Viewer, posts []entity.Post) error {
+	args := m.Called(viewer, posts)
+	for i := range posts {
+		posts[i].Reactions = map[entity.ReactionKind]int64{}
+		posts[i].MyReactions = []entity.ReactionKind{}
+	}
+	return args.Error(0)
+}
+
+func TestAdd(t *testing.T) {
+	viewer := post.Viewer{UserID: 3, Role: entity.RoleUser}
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockPosts := new(MockPostService)
+		service := bookmark.NewService(mockRepo, mockPosts)
+
+		mockPosts.On("GetByID", uint(1), viewer).Return(&entity.Post{ID: 1, Status: entity.PostStatusPublished}, nil)
+		mockRepo.On("Create", &entity.Bookmark{UserID: 3, PostID: 1}).Return(nil)
+
+		result, err := service.Add(1, viewer)
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(1), result.PostID)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Hidden Post", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockPosts := new(MockPostService)
+		service := bookmark.NewService(mockRepo, mockPosts)
+
+		mockPosts.On("GetByID", uint(1), viewer).Return(nil, pkgdb.ErrRecordNotFound)
+
+		_, err := service.Add(1, viewer)
+
+		assert.ErrorIs(t, err, pkgdb.ErrRecordNotFound)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+}
+
+func TestList(t *testing.T) {
+	t.Run("Defaults", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockPosts := new(MockPostService)
+		service := bookmark.NewService(mockRepo, mockPosts)
+		viewer := post.Viewer{UserID: 3, Role: entity.RoleUser}
+
+		mockRepo.On("FindPosts", uint(3), false, 0, 20).Return([]entity.Post{{ID: 1}, {ID: 2}}, nil)
+		mockRepo.On("CountPosts", uint(3), false).Return(int64(2), nil)
+		mockPosts.On("AttachReactions", viewer, mock.Anything).Return(nil)
+
+		result, err := service.List(viewer, bookmark.ListQuery{})
+
+		assert.NoError(t, err)
+		assert.Len(t, result.Posts, 2)
+		assert.NotNil(t, result.Posts[0].Reactions)
+		assert.NotNil(t, result.Posts[1].MyReactions)
+		mockPosts.AssertExpectations(t)
+		assert.Equal(t, 1, result.Page)
+		assert.Equal(t, 20, result.PerPage)
+		assert.False(t, result.HasMore)
+	})
+
+	t.Run("Has More", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockPosts := new(MockPostService)
+		service := bookmark.NewService(mockRepo, mockPosts)
+		mockPosts.On("AttachReactions", mock.Anything, mock.Anything).Return(nil)
+
+		mockRepo.On("FindPosts", uint(3), true, 2, 2).Return([]entity.Post{{ID: 3}, {ID: 4}}, nil)
+		mockRepo.On("CountPosts", uint(3), true).Return(int64(5), nil)
+
+		result, err := service.List(post.Viewer{UserID: 3, Role: entity.RoleAdmin}, bookmark.ListQuery{Page: 2, PerPage: 2})
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(5), result.Total)
+		assert.True(t, result.HasMore)
+	})
+}
@@ -0,0 +1,111 @@
+package comment
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	pkgdb "post/internal/pkg/database"
+	"post/internal/pkg/response"
+	"post/internal/post"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service}
+}
+
+func (h *Handler) GetComments(c *gin.Context) {
+	postID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid ID", err.Error())
+		return
+	}
+
+	comments, err := h.service.List(uint(postID), post.ViewerFrom(c))
+	if err != nil {
+		writeError(c, err, "Failed to fetch comments")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Comments retrieved", NewViews(comments, post.ViewerFrom(c)))
+}
+
+func (h *Handler) CreateComment(c *gin.Context) {
+	postID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid ID", err.Error())
+		return
+	}
+
+	var input CreateCommentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+
+	comment, err := h.service.Create(uint(postID), post.ViewerFrom(c), input)
+	if err != nil {
+		writeError(c, err, "Failed to create comment")
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "Comment created successfully", NewView(comment, post.ViewerFrom(c)))
+}
+
+func (h *Handler) UpdateComment(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid ID", err.Error())
+		return
+	}
+
+	var input UpdateCommentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+
+	comment, err := h.service.Update(uint(id), post.ViewerFrom(c), input)
+	if err != nil {
+		writeError(c, err, "Failed to update comment")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Comment updated successfully", NewView(comment, post.ViewerFrom(c)))
+}
+
+func (h *Handler) DeleteComment(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid ID", err.Error())
+		return
+	}
+
+	if err := h.service.DeleteOwned(uint(id), post.ViewerFrom(c)); err != nil {
+		writeError(c, err, "Failed to delete comment")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Comment deleted successfully", nil)
+}
+
+func writeError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, pkgdb.ErrRecordNotFound):
+		response.Error(c, http.StatusNotFound, "Not found", nil)
+	case errors.Is(err, ErrForbidden):
+		response.Error(c, http.StatusForbidden, "Forbidden", err.Error())
+	case errors.Is(err, ErrCommentsClosed):
+		response.Error(c, http.StatusConflict, "Comments closed", err.Error())
+	case errors.Is(err, ErrParentNotFound), errors.Is(err, ErrTooDeep):
+		response.Error(c, http.StatusBadRequest, "Invalid input", err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, message, err.Error())
+	}
+}
@@ -0,0 +1,66 @@
+package comment
+
+import (
+	"post/internal/entity"
+
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	Create(comment *entity.Comment) error
+	FindByID(id uint) (*entity.Comment, error)
+	FindByPostID(postID uint) ([]entity.Comment, error)
+	FindRecent(limit int) ([]entity.Comment, error)
+	Update(comment *entity.Comment) error
+	Delete(id uint) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db}
+}
+
+func (r *repository) Create(comment *entity.Comment) error {
+	if err := r.db.Create(comment).Error; err != nil {
+		return err
+	}
+	return r.db.Preload("User.Profile").First(comment, comment.ID).Error
+}
+
+func (r *repository) FindByID(id uint) (*entity.Comment, error) {
+	var comment entity.Comment
+	err := r.db.Preload("User.Profile").First(&comment, id).Error
+	return &comment, err
+}
+
+// FindByPostID returns every comment on the post in thread order, including
+// deleted ones so their replies still have a place in the thread.
+func (r *repository) FindByPostID(postID uint) ([]entity.Comment, error) {
+	var comments []entity.Comment
+	err := r.db.Unscoped().Preload("User.Profile").
+		Where("post_id = ?", postID).
+		Order("created_at, id").
+		Find(&comments).Error
+	return comments, err
+}
+
+// FindRecent returns the newest comments across all posts for moderation.
+func (r *repository) FindRecent(limit int) ([]entity.Comment, error) {
+	var comments []entity.Comment
+	err := r.db.Preload("User.Profile").Preload("Post").
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Find(&comments).Error
+	return comments, err
+}
+
+func (r *repository) Update(comment *entity.Comment) error {
+	return r.db.Model(comment).Update("body", comment.Body).Error
+}
+
+func (r *repository) Delete(id uint) error {
+	return r.db.Delete(&entity.Comment{}, id).Error
+}
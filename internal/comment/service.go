@@ -0,0 +1,175 @@
+package comment
+
+import (
+	"errors"
+	"fmt"
+
+	"post/internal/entity"
+	pkgdb "post/internal/pkg/database"
+	"post/internal/post"
+)
+
+var (
+	ErrForbidden      = errors.New("you are not allowed to modify this comment")
+	ErrCommentsClosed = errors.New("comments are only open on published posts")
+	ErrParentNotFound = errors.New("parent comment not found on this post")
+	ErrTooDeep        = errors.New("reply is nested too deeply")
+)
+
+// recentLimit is how many comments the moderation page shows.
+const recentLimit = 100
+
+type Service interface {
+	List(postID uint, viewer post.Viewer) ([]entity.Comment, error)
+	Create(postID uint, viewer post.Viewer, input CreateCommentInput) (*entity.Comment, error)
+	Update(id uint, viewer post.Viewer, input UpdateCommentInput) (*entity.Comment, error)
+	DeleteOwned(id uint, viewer post.Viewer) error
+	Delete(id uint) error
+	GetRecent() ([]entity.Comment, error)
+}
+
+type service struct {
+	repo     Repository
+	posts    post.Service
+	maxDepth int
+}
+
+// NewService returns a comment service. Replies nest at most maxDepth levels
+// below a top-level comment.
+func NewService(repo Repository, posts post.Service, maxDepth int) Service {
+	return &service{repo, posts, maxDepth}
+}
+
+type CreateCommentInput struct {
+	Body     string `json:"body" binding:"required,max=10000"`
+	ParentID *uint  `json:"parent_id"`
+}
+
+type UpdateCommentInput struct {
+	Body string `json:"body" binding:"required,max=10000"`
+}
+
+// List returns the post's comments as a tree of top-level comments with
+// their replies. Deleted comments that still have replies are kept with
+// their body and author removed.
+func (s *service) List(postID uint, viewer post.Viewer) ([]entity.Comment, error) {
+	if _, err := s.posts.GetByID(postID, viewer); err != nil {
+		return nil, err
+	}
+
+	comments, err := s.repo.FindByPostID(postID)
+	if err != nil {
+		return nil, err
+	}
+	return buildTree(comments), nil
+}
+
+func (s *service) Create(postID uint, viewer post.Viewer, input CreateCommentInput) (*entity.Comment, error) {
+	p, err := s.posts.GetByID(postID, viewer)
+	if err != nil {
+		return nil, err
+	}
+	if p.Status != entity.PostStatusPublished {
+		return nil, ErrCommentsClosed
+	}
+
+	comment := &entity.Comment{
+		PostID:   postID,
+		UserID:   viewer.UserID,
+		ParentID: input.ParentID,
+		Body:     input.Body,
+	}
+	if input.ParentID != nil {
+		parent, err := s.repo.FindByID(*input.ParentID)
+		if err != nil {
+			if err = pkgdb.ParseError(err); err == pkgdb.ErrRecordNotFound {
+				return nil, ErrParentNotFound
+			}
+			return nil, err
+		}
+		if parent.PostID != postID {
+			return nil, ErrParentNotFound
+		}
+		if parent.Depth >= s.maxDepth {
+			return nil, fmt.Errorf("%w: replies can nest at most %d levels", ErrTooDeep, s.maxDepth)
+		}
+		comment.Depth = parent.Depth + 1
+	}
+
+	if err := s.repo.Create(comment); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// Update changes a comment's body. Only its author can edit it, admins
+// moderate by deleting.
+func (s *service) Update(id uint, viewer post.Viewer, input UpdateCommentInput) (*entity.Comment, error) {
+	comment, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, pkgdb.ParseError(err)
+	}
+	if viewer.UserID == 0 || comment.UserID != viewer.UserID {
+		return nil, ErrForbidden
+	}
+
+	comment.Body = input.Body
+	if err := s.repo.Update(comment); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// DeleteOwned deletes a comment on behalf of its author or a moderator.
+func (s *service) DeleteOwned(id uint, viewer post.Viewer) error {
+	comment, err := s.repo.FindByID(id)
+	if err != nil {
+		return pkgdb.ParseError(err)
+	}
+	if viewer.UserID == 0 || (comment.UserID != viewer.UserID && !viewer.Can(entity.PermCommentsDeleteAny)) {
+		return ErrForbidden
+	}
+	return s.repo.Delete(id)
+}
+
+func (s *service) Delete(id uint) error {
+	return s.repo.Delete(id)
+}
+
+func (s *service) GetRecent() ([]entity.Comment, error) {
+	return s.repo.FindRecent(recentLimit)
+}
+
+// buildTree nests comments under their parents, keeping the given order
+// among siblings. Deleted comments without live replies are dropped.
+func buildTree(comments []entity.Comment) []entity.Comment {
+	children := make(map[uint][]int, len(comments))
+	var roots []int
+	for i, c := range comments {
+		if c.ParentID == nil {
+			roots = append(roots, i)
+		} else {
+			children[*c.ParentID] = append(children[*c.ParentID], i)
+		}
+	}
+
+	var build func(indexes []int) []entity.Comment
+	build = func(indexes []int) []entity.Comment {
+		nodes := []entity.Comment{}
+		for _, i := range indexes {
+			c := comments[i]
+			c.Replies = build(children[c.ID])
+			if c.DeletedAt.Valid {
+				if len(c.Replies) == 0 {
+					continue
+				}
+				c.Body = ""
+				c.UserID = 0
+				c.User = entity.User{}
+			}
+			nodes = append(nodes, c)
+		}
+		return nodes
+	}
+	return build(roots)
+}
@@ -0,0 +1,266 @@
+package comment_test
+
+import (
+	"testing"
+	"time"
+
+	"post/internal/comment"
+	"post/internal/entity"
+	pkgdb "post/internal/pkg/database"
+	"post/internal/post"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// MockRepository is a mock of comment.Repository
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) Create(c *entity.Comment) error {
+	args := m.Called(c)
+	return args.Error(0)
+}
+
+func (m *MockRepository) FindByID(id uint) (*entity.Comment, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Comment), args.Error(1)
+}
+
+func (m *MockRepository) FindByPostID(postID uint) ([]entity.Comment, error) {
+	args := m.Called(postID)
+	return args.Get(0).([]entity.Comment), args.Error(1)
+}
+
+func (m *MockRepository) FindRecent(limit int) ([]entity.Comment, error) {
+	args := m.Called(limit)
+	return args.Get(0).([]entity.Comment), args.Error(1)
+}
+
+func (m *MockRepository) Update(c *entity.Comment) error {
+	args := m.Called(c)
+	return args.Error(0)
+}
+
+func (m *MockRepository) Delete(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+// MockPostService mocks the part of post.Service comments rely on. Calling
+// any other method panics.
+type MockPostService struct {
+	mock.Mock
+	post.Service
+}
+
+func (m *MockPostService) GetByID(id uint, viewer post.Viewer) (*entity.Post, error) {
+	args := m.Called(id, viewer)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Post), args.Error(1)
+}
+
+func uintPtr(v uint) *uint {
+	return &v
+}
+
+func TestList(t *testing.T) {
+	t.Run("Tree", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockPosts := new(MockPostService)
+		service := comment.NewService(mockRepo, mockPosts, 5)
+		deleted := gorm.DeletedAt{Time: time.Now(), Valid: true}
+
+		mockPosts.On("GetByID", uint(1), post.Viewer{}).Return(&entity.Post{ID: 1, Status: entity.PostStatusPublished}, nil)
+		mockRepo.On("FindByPostID", uint(1)).Return([]entity.Comment{
+			{ID: 1, UserID: 7, Body: "first"},
+			{ID: 2, UserID: 8, Body: "removed", DeletedAt: deleted},
+			{ID: 3, UserID: 9, Body: "reply", ParentID: uintPtr(2), Depth: 1},
+			{ID: 4, UserID: 9, Body: "gone", ParentID: uintPtr(1), Depth: 1, DeletedAt: deleted},
+			{ID: 5, UserID: 7, Body: "second reply", ParentID: uintPtr(2), Depth: 1},
+		}, nil)
+
+		result, err := service.List(1, post.Viewer{})
+
+		assert.NoError(t, err)
+		assert.Len(t, result, 2)
+		assert.Equal(t, "first", result[0].Body)
+		assert.Empty(t, result[0].Replies)
+		assert.Equal(t, uint(2), result[1].ID)
+		assert.Empty(t, result[1].Body)
+		assert.Zero(t, result[1].UserID)
+		assert.Len(t, result[1].Replies, 2)
+		assert.Equal(t, "reply", result[1].Replies[0].Body)
+		assert.Equal(t, "second reply", result[1].Replies[1].Body)
+	})
+
+	t.Run("Hidden Post", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockPosts := new(MockPostService)
+		service := comment.NewService(mockRepo, mockPosts, 5)
+
+		mockPosts.On("GetByID", uint(1), post.Viewer{}).Return(nil, pkgdb.ErrRecordNotFound)
+
+		result, err := service.List(1, post.Viewer{})
+
+		assert.ErrorIs(t, err, pkgdb.ErrRecordNotFound)
+		assert.Nil(t, result)
+		mockRepo.AssertNotCalled(t, "FindByPostID", uint(1))
+	})
+}
+
+func TestCreate(t *testing.T) {
+	viewer := post.Viewer{UserID: 3, Role: entity.RoleUser}
+	published := &entity.Post{ID: 1, Status: entity.PostStatusPublished}
+
+	t.Run("Top Level", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockPosts := new(MockPostService)
+		service := comment.NewService(mockRepo, mockPosts, 5)
+
+		mockPosts.On("GetByID", uint(1), viewer).Return(published, nil)
+		mockRepo.On("Create", mock.MatchedBy(func(c *entity.Comment) bool {
+			return c.PostID == 1 && c.UserID == 3 && c.ParentID == nil && c.Depth == 0 && c.Body == "hi"
+		})).Return(nil)
+
+		result, err := service.Create(1, viewer, comment.CreateCommentInput{Body: "hi"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "hi", result.Body)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Reply", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockPosts := new(MockPostService)
+		service := comment.NewService(mockRepo, mockPosts, 5)
+
+		mockPosts.On("GetByID", uint(1), viewer).Return(published, nil)
+		mockRepo.On("FindByID", uint(10)).Return(&entity.Comment{ID: 10, PostID: 1, Depth: 2}, nil)
+		mockRepo.On("Create", mock.MatchedBy(func(c *entity.Comment) bool {
+			return *c.ParentID == 10 && c.Depth == 3
+		})).Return(nil)
+
+		_, err := service.Create(1, viewer, comment.CreateCommentInput{Body: "hi", ParentID: uintPtr(10)})
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Too Deep", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockPosts := new(MockPostService)
+		service := comment.NewService(mockRepo, mockPosts, 2)
+
+		mockPosts.On("GetByID", uint(1), viewer).Return(published, nil)
+		mockRepo.On("FindByID", uint(10)).Return(&entity.Comment{ID: 10, PostID: 1, Depth: 2}, nil)
+
+		_, err := service.Create(1, viewer, comment.CreateCommentInput{Body: "hi", ParentID: uintPtr(10)})
+
+		assert.ErrorIs(t, err, comment.ErrTooDeep)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("Parent On Other Post", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockPosts := new(MockPostService)
+		service := comment.NewService(mockRepo, mockPosts, 5)
+
+		mockPosts.On("GetByID", uint(1), viewer).Return(published, nil)
+		mockRepo.On("FindByID", uint(10)).Return(&entity.Comment{ID: 10, PostID: 2}, nil)
+
+		_, err := service.Create(1, viewer, comment.CreateCommentInput{Body: "hi", ParentID: uintPtr(10)})
+
+		assert.ErrorIs(t, err, comment.ErrParentNotFound)
+	})
+
+	t.Run("Parent Deleted", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockPosts := new(MockPostService)
+		service := comment.NewService(mockRepo, mockPosts, 5)
+
+		mockPosts.On("GetByID", uint(1), viewer).Return(published, nil)
+		mockRepo.On("FindByID", uint(10)).Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := service.Create(1, viewer, comment.CreateCommentInput{Body: "hi", ParentID: uintPtr(10)})
+
+		assert.ErrorIs(t, err, comment.ErrParentNotFound)
+	})
+
+	t.Run("Draft Post", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockPosts := new(MockPostService)
+		service := comment.NewService(mockRepo, mockPosts, 5)
+		author := post.Viewer{UserID: 1, Role: entity.RoleUser}
+
+		mockPosts.On("GetByID", uint(1), author).Return(&entity.Post{ID: 1, UserID: 1, Status: entity.PostStatusDraft}, nil)
+
+		_, err := service.Create(1, author, comment.CreateCommentInput{Body: "hi"})
+
+		assert.ErrorIs(t, err, comment.ErrCommentsClosed)
+	})
+}
+
+func TestUpdate(t *testing.T) {
+	t.Run("Author", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := comment.NewService(mockRepo, new(MockPostService), 5)
+
+		mockRepo.On("FindByID", uint(1)).Return(&entity.Comment{ID: 1, UserID: 3, Body: "old"}, nil)
+		mockRepo.On("Update", mock.MatchedBy(func(c *entity.Comment) bool {
+			return c.Body == "new"
+		})).Return(nil)
+
+		result, err := service.Update(1, post.Viewer{UserID: 3, Role: entity.RoleUser}, comment.UpdateCommentInput{Body: "new"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "new", result.Body)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Admin Cannot Edit", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := comment.NewService(mockRepo, new(MockPostService), 5)
+
+		mockRepo.On("FindByID", uint(1)).Return(&entity.Comment{ID: 1, UserID: 3, Body: "old"}, nil)
+
+		_, err := service.Update(1, post.Viewer{UserID: 99, Role: entity.RoleAdmin}, comment.UpdateCommentInput{Body: "new"})
+
+		assert.ErrorIs(t, err, comment.ErrForbidden)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+}
+
+func TestDeleteOwned(t *testing.T) {
+	t.Run("Admin", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := comment.NewService(mockRepo, new(MockPostService), 5)
+
+		mockRepo.On("FindByID", uint(1)).Return(&entity.Comment{ID: 1, UserID: 3}, nil)
+		mockRepo.On("Delete", uint(1)).Return(nil)
+
+		err := service.DeleteOwned(1, post.Viewer{UserID: 99, Role: entity.RoleAdmin})
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := comment.NewService(mockRepo, new(MockPostService), 5)
+
+		mockRepo.On("FindByID", uint(1)).Return(&entity.Comment{ID: 1, UserID: 3}, nil)
+
+		err := service.DeleteOwned(1, post.Viewer{UserID: 4, Role: entity.RoleUser})
+
+		assert.ErrorIs(t, err, comment.ErrForbidden)
+		mockRepo.AssertNotCalled(t, "Delete", uint(1))
+	})
+}
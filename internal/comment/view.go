@@ -0,0 +1,31 @@
+package comment
+
+import (
+	"post/internal/entity"
+	"post/internal/post"
+	"post/internal/user"
+)
+
+// View is a comment as the API returns it, with its author shown the way the
+// viewer may see them.
+type View struct {
+	entity.Comment
+	User    any    `json:"user,omitempty"`
+	Replies []View `json:"replies"`
+}
+
+func NewView(comment *entity.Comment, viewer post.Viewer) View {
+	return View{
+		Comment: *comment,
+		User:    user.View(&comment.User, viewer.UserID, viewer.Role),
+		Replies: NewViews(comment.Replies, viewer),
+	}
+}
+
+func NewViews(comments []entity.Comment, viewer post.Viewer) []View {
+	views := make([]View, len(comments))
+	for i := range comments {
+		views[i] = NewView(&comments[i], viewer)
+	}
+	return views
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"post/internal/comment"
 	"post/internal/pkg/response"
 	"post/internal/post"
 	"post/internal/user"
@@ -12,12 +13,13 @@ import (
 )
 
 type Handler struct {
-	userService user.Service
-	postService post.Service
+	userService    user.Service
+	postService    post.Service
+	commentService comment.Service
 }
 
-func NewHandler(userService user.Service, postService post.Service) *Handler {
-	return &Handler{userService, postService}
+func NewHandler(userService user.Service, postService post.Service, commentService comment.Service) *Handler {
+	return &Handler{userService, postService, commentService}
 }
 
 func (h *Handler) ServeIndex(c *gin.Context) {
@@ -40,9 +42,14 @@ func (h *Handler) ServeUsers(c *gin.Context) {
 		return
 	}
 
+	views := make([]user.AdminUser, len(users))
+	for i := range users {
+		views[i] = user.NewAdminUser(&users[i])
+	}
+
 	data := gin.H{
 		"Page":       "users",
-		"Users":      users,
+		"Users":      views,
 		"CurrentURL": "/admin/users",
 	}
 	c.HTML(http.StatusOK, "base.html", data)
@@ -55,14 +62,39 @@ func (h *Handler) ServePosts(c *gin.Context) {
 		return
 	}
 
+	views := make([]post.View, len(posts))
+	for i := range posts {
+		views[i] = post.View{Post: posts[i], User: user.NewAdminUser(&posts[i].User)}
+	}
+
 	data := gin.H{
 		"Page":       "posts",
-		"Posts":      posts,
+		"Posts":      views,
 		"CurrentURL": "/admin/posts",
 	}
 	c.HTML(http.StatusOK, "base.html", data)
 }
 
+func (h *Handler) ServeComments(c *gin.Context) {
+	comments, err := h.commentService.GetRecent()
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "comments.html", gin.H{"Error": err.Error()})
+		return
+	}
+
+	views := make([]comment.View, len(comments))
+	for i := range comments {
+		views[i] = comment.View{Comment: comments[i], User: user.NewAdminUser(&comments[i].User)}
+	}
+
+	data := gin.H{
+		"Page":       "comments",
+		"Comments":   views,
+		"CurrentURL": "/admin/comments",
+	}
+	c.HTML(http.StatusOK, "base.html", data)
+}
+
 func (h *Handler) DeleteUser(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
@@ -94,3 +126,19 @@ func (h *Handler) DeletePost(c *gin.Context) {
 
 	c.Status(http.StatusOK)
 }
+
+func (h *Handler) DeleteComment(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid ID", err.Error())
+		return
+	}
+
+	if err := h.commentService.Delete(uint(id)); err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to delete comment", err.Error())
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
@@ -0,0 +1,10 @@
+package entity
+
+import "time"
+
+// Bookmark saves a post to a user's reading list.
+type Bookmark struct {
+	UserID    uint      `gorm:"primaryKey;autoIncrement:false" json:"user_id"`
+	PostID    uint      `gorm:"primaryKey;autoIncrement:false" json:"post_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,26 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Comment is a reply to a post or, when ParentID is set, to another comment.
+// Depth is 0 for top-level comments and one more than the parent for replies.
+type Comment struct {
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+
+	PostID   uint   `gorm:"not null;index" json:"post_id"`
+	Post     *Post  `json:"post,omitempty"`
+	UserID   uint   `gorm:"not null" json:"user_id"`
+	User     User   `json:"user"`
+	ParentID *uint  `gorm:"index" json:"parent_id"`
+	Depth    int    `gorm:"not null" json:"depth"`
+	Body     string `gorm:"not null" json:"body"`
+
+	Replies []Comment `gorm:"-" json:"replies"`
+}
@@ -0,0 +1,10 @@
+package entity
+
+import "time"
+
+// Follow makes FollowerID see FolloweeID's posts in their feed.
+type Follow struct {
+	FollowerID uint      `gorm:"primaryKey;autoIncrement:false" json:"follower_id"`
+	FolloweeID uint      `gorm:"primaryKey;autoIncrement:false;index:idx_follows_followee_id_created_at,priority:1" json:"followee_id"`
+	CreatedAt  time.Time `gorm:"index:idx_follows_followee_id_created_at,priority:2" json:"created_at"`
+}
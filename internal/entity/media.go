@@ -0,0 +1,52 @@
+package entity
+
+import "time"
+
+// MediaStatus tracks an uploaded image through background processing. Plain
+// uploads are ready as soon as they are stored.
+type MediaStatus string
+
+const (
+	MediaStatusPending    MediaStatus = "pending"
+	MediaStatusProcessing MediaStatus = "processing"
+	MediaStatusReady      MediaStatus = "ready"
+	MediaStatusFailed     MediaStatus = "failed"
+)
+
+// Media is a file uploaded by a user. Key locates it in storage and URL is
+// where clients download it. URL stays empty until the file is ready.
+type Media struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	UserID      uint        `gorm:"not null;index" json:"user_id"`
+	PostID      *uint       `gorm:"index" json:"post_id,omitempty"`
+	Key         string      `gorm:"uniqueIndex;not null" json:"-"`
+	Filename    string      `gorm:"not null" json:"filename"`
+	ContentType string      `gorm:"not null" json:"content_type"`
+	Size        int64       `gorm:"not null" json:"size"`
+	URL         string      `gorm:"not null" json:"url"`
+	Status      MediaStatus `gorm:"type:text;not null;default:ready;index" json:"status"`
+
+	// Width, Height and Blurhash are filled in once an image is processed.
+	Width    int            `gorm:"not null;default:0" json:"width,omitempty"`
+	Height   int            `gorm:"not null;default:0" json:"height,omitempty"`
+	Blurhash string         `gorm:"not null;default:''" json:"blurhash,omitempty"`
+	Variants []MediaVariant `json:"variants,omitempty"`
+}
+
+// MediaVariant is a resized copy of a processed image, like a thumbnail.
+type MediaVariant struct {
+	ID        uint      `gorm:"primaryKey" json:"-"`
+	CreatedAt time.Time `json:"-"`
+
+	MediaID     uint   `gorm:"not null;index" json:"-"`
+	Name        string `gorm:"not null" json:"name"`
+	Key         string `gorm:"uniqueIndex;not null" json:"-"`
+	URL         string `gorm:"not null" json:"url"`
+	ContentType string `gorm:"not null" json:"content_type"`
+	Width       int    `gorm:"not null" json:"width"`
+	Height      int    `gorm:"not null" json:"height"`
+	Size        int64  `gorm:"not null" json:"size"`
+}
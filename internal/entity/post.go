@@ -6,14 +6,52 @@ import (
 	"gorm.io/gorm"
 )
 
+type PostStatus string
+
+const (
+	PostStatusDraft     PostStatus = "draft"
+	PostStatusScheduled PostStatus = "scheduled"
+	PostStatusPublished PostStatus = "published"
+	PostStatusArchived  PostStatus = "archived"
+)
+
+// ContentFormat tells how a post's content is written and rendered.
+type ContentFormat string
+
+const (
+	ContentFormatPlain    ContentFormat = "plain"
+	ContentFormatMarkdown ContentFormat = "markdown"
+)
+
 type Post struct {
-	ID        uint           `gorm:"primaryKey" json:"id"`
-	CreatedAt time.Time      `json:"created_at"`
+	ID        uint           `gorm:"primaryKey;index:idx_posts_created_at_id,priority:2" json:"id"`
+	CreatedAt time.Time      `gorm:"index:idx_posts_created_at_id,priority:1;index:idx_posts_user_id_created_at,priority:2" json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
 
-	UserID  uint   `gorm:"not null" json:"user_id"`
+	UserID  uint   `gorm:"not null;index:idx_posts_user_id_created_at,priority:1" json:"user_id"`
 	User    User   `json:"user"`
 	Title   string `gorm:"not null" json:"title"`
+	Slug    string `gorm:"uniqueIndex;not null" json:"slug"`
 	Content string `gorm:"not null" json:"content"`
+	Tags    []Tag  `gorm:"many2many:post_tags;" json:"tags"`
+
+	// ContentHTML, Excerpt and ReadingTime (in minutes) are derived from
+	// Content whenever it changes.
+	ContentFormat ContentFormat `gorm:"type:text;not null;default:plain" json:"content_format"`
+	ContentHTML   string        `gorm:"not null" json:"content_html"`
+	Excerpt       string        `gorm:"not null" json:"excerpt"`
+	ReadingTime   int           `gorm:"not null" json:"reading_time"`
+
+	Status      PostStatus `gorm:"type:text;not null;default:draft;index" json:"status"`
+	PublishedAt *time.Time `json:"published_at"`
+	PublishAt   *time.Time `gorm:"index" json:"publish_at,omitempty"`
+
+	// Reactions counts each kind of reaction and MyReactions holds the
+	// caller's own. Both are filled in per request.
+	Reactions   map[ReactionKind]int64 `gorm:"-" json:"reactions"`
+	MyReactions []ReactionKind         `gorm:"-" json:"my_reactions"`
+
+	// SearchVector is maintained by Postgres and only used in WHERE clauses.
+	SearchVector string `gorm:"->:false;<-:false;type:tsvector GENERATED ALWAYS AS (setweight(to_tsvector('english', coalesce(title, '')), 'A') || setweight(to_tsvector('english', coalesce(content, '')), 'B')) STORED;index:idx_posts_search_vector,type:gin" json:"-"`
 }
@@ -0,0 +1,36 @@
+package entity
+
+import (
+	"slices"
+	"time"
+)
+
+// ReactionKind names one of the reactions readers can leave on a post.
+type ReactionKind string
+
+const (
+	ReactionLike      ReactionKind = "like"      // 👍
+	ReactionLove      ReactionKind = "love"      // ❤️
+	ReactionLaugh     ReactionKind = "laugh"     // 😂
+	ReactionWow       ReactionKind = "wow"       // 😮
+	ReactionSad       ReactionKind = "sad"       // 😢
+	ReactionCelebrate ReactionKind = "celebrate" // 🎉
+)
+
+// ReactionKinds lists the supported reactions.
+var ReactionKinds = []ReactionKind{
+	ReactionLike, ReactionLove, ReactionLaugh, ReactionWow, ReactionSad, ReactionCelebrate,
+}
+
+func (k ReactionKind) Valid() bool {
+	return slices.Contains(ReactionKinds, k)
+}
+
+// PostReaction is one user's reaction of one kind to a post. A user can leave
+// several kinds on the same post, but each kind only once.
+type PostReaction struct {
+	PostID    uint         `gorm:"primaryKey;autoIncrement:false" json:"post_id"`
+	UserID    uint         `gorm:"primaryKey;autoIncrement:false" json:"user_id"`
+	Kind      ReactionKind `gorm:"primaryKey;type:text" json:"kind"`
+	CreatedAt time.Time    `json:"created_at"`
+}
@@ -0,0 +1,18 @@
+package entity
+
+import (
+	"time"
+)
+
+// PostRevision is a snapshot of a post's title and content after an edit.
+// Revision 1 is the post as it was created.
+type PostRevision struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	PostID   uint   `gorm:"not null;uniqueIndex:idx_post_revisions_post_number,priority:1" json:"post_id"`
+	Number   int    `gorm:"not null;uniqueIndex:idx_post_revisions_post_number,priority:2" json:"number"`
+	Title    string `gorm:"not null" json:"title"`
+	Content  string `gorm:"not null" json:"content"`
+	EditorID uint   `gorm:"not null" json:"editor_id"`
+}
@@ -0,0 +1,15 @@
+package entity
+
+import (
+	"time"
+)
+
+// PostSlug is a slug a post had before its title changed. It is kept so
+// links using the old slug can be redirected.
+type PostSlug struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	PostID uint   `gorm:"not null;index" json:"post_id"`
+	Slug   string `gorm:"uniqueIndex;not null" json:"slug"`
+}
@@ -12,7 +12,15 @@ type Profile struct {
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
 
-	UserID uint   `gorm:"uniqueIndex;not null" json:"user_id"`
-	Name   string `json:"name"`
-	Bio    string `json:"bio"`
+	UserID uint `gorm:"uniqueIndex;not null" json:"user_id"`
+	// Handle is the unique, lower-case name the profile is found by, written
+	// as @handle. It is nil until the user picks one.
+	Handle      *string  `gorm:"uniqueIndex" json:"handle"`
+	Name        string   `json:"name"`
+	Bio         string   `json:"bio"`
+	AvatarURL   string   `gorm:"not null;default:''" json:"avatar_url"`
+	Website     string   `gorm:"not null;default:''" json:"website"`
+	Location    string   `gorm:"not null;default:''" json:"location"`
+	Pronouns    string   `gorm:"not null;default:''" json:"pronouns"`
+	SocialLinks []string `gorm:"serializer:json;type:jsonb;not null;default:'[]'" json:"social_links"`
 }
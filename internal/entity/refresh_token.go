@@ -0,0 +1,17 @@
+package entity
+
+import "time"
+
+// RefreshToken records an issued refresh token by its jti. Each refresh
+// hands out a new token in the same family and marks the old one used, so an
+// old token coming back means it was copied and the whole family is revoked.
+// The family is the ID of the session the tokens belong to.
+type RefreshToken struct {
+	ID        string     `gorm:"primaryKey;type:uuid" json:"id"`
+	FamilyID  string     `gorm:"type:uuid;not null;index" json:"family_id"`
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	ExpiresAt time.Time  `gorm:"not null;index" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
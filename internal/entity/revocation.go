@@ -0,0 +1,21 @@
+package entity
+
+import "time"
+
+// RevokedToken blocks a single token by its jti until it would have expired
+// anyway.
+type RevokedToken struct {
+	ID        string    `gorm:"primaryKey;type:uuid" json:"id"`
+	UserID    uint      `gorm:"not null;index" json:"user_id"`
+	ExpiresAt time.Time `gorm:"not null;index" json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// UserRevocation blocks every token of the user issued at or before
+// RevokedBefore. It can go once ExpiresAt has passed, as all of those tokens
+// have expired by then.
+type UserRevocation struct {
+	UserID        uint      `gorm:"primaryKey;autoIncrement:false" json:"user_id"`
+	RevokedBefore time.Time `gorm:"not null" json:"revoked_before"`
+	ExpiresAt     time.Time `gorm:"not null;index" json:"expires_at"`
+}
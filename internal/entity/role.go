@@ -0,0 +1,71 @@
+package entity
+
+import "slices"
+
+// Role decides what a user may do beyond managing their own content. The
+// values are stored in users.role, so existing ones must never change.
+type Role int
+
+const (
+	RoleAdmin  Role = 1
+	RoleUser   Role = 2
+	RoleEditor Role = 3
+)
+
+// Roles lists the assignable roles, most privileged first.
+var Roles = []Role{RoleAdmin, RoleEditor, RoleUser}
+
+func (r Role) Valid() bool {
+	return slices.Contains(Roles, r)
+}
+
+func (r Role) String() string {
+	switch r {
+	case RoleAdmin:
+		return "admin"
+	case RoleEditor:
+		return "editor"
+	case RoleUser:
+		return "user"
+	default:
+		return "unknown"
+	}
+}
+
+// Permission names one action a role may take on content it does not own,
+// written as resource:action:scope.
+type Permission string
+
+const (
+	PermPostsReadAny      Permission = "posts:read:any"
+	PermPostsUpdateAny    Permission = "posts:update:any"
+	PermPostsDeleteAny    Permission = "posts:delete:any"
+	PermCommentsDeleteAny Permission = "comments:delete:any"
+	PermMediaDeleteAny    Permission = "media:delete:any"
+	PermUsersReadEmail    Permission = "users:read:email"
+	PermUsersUpdateRole   Permission = "users:update:role"
+)
+
+// rolePermissions grants each role its permissions. Plain users get none:
+// everything they can do is covered by owning the content.
+var rolePermissions = map[Role][]Permission{
+	RoleAdmin: {
+		PermPostsReadAny, PermPostsUpdateAny, PermPostsDeleteAny,
+		PermCommentsDeleteAny, PermMediaDeleteAny,
+		PermUsersReadEmail, PermUsersUpdateRole,
+	},
+	RoleEditor: {
+		PermPostsReadAny, PermPostsUpdateAny, PermPostsDeleteAny,
+		PermCommentsDeleteAny, PermMediaDeleteAny,
+	},
+}
+
+// Permissions returns the permissions granted to the role.
+func (r Role) Permissions() []Permission {
+	return rolePermissions[r]
+}
+
+// Can reports whether the role grants the permission.
+func (r Role) Can(p Permission) bool {
+	return slices.Contains(rolePermissions[r], p)
+}
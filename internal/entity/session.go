@@ -0,0 +1,17 @@
+package entity
+
+import "time"
+
+// Session is one signin on one device. Its ID is the family of the refresh
+// tokens issued to it and every token carries it as sid, so revoking the
+// session cuts off all of them at once.
+type Session struct {
+	ID         string     `gorm:"primaryKey;type:uuid" json:"id"`
+	UserID     uint       `gorm:"not null;index" json:"-"`
+	UserAgent  string     `gorm:"not null;default:''" json:"user_agent"`
+	IP         string     `gorm:"not null;default:''" json:"ip"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt time.Time  `gorm:"not null" json:"last_seen_at"`
+	ExpiresAt  time.Time  `gorm:"not null;index" json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
@@ -0,0 +1,14 @@
+package entity
+
+import (
+	"time"
+)
+
+type Tag struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Name  string `gorm:"uniqueIndex;not null" json:"name"`
+	Posts []Post `gorm:"many2many:post_tags;" json:"posts,omitempty"`
+}
@@ -6,13 +6,6 @@ import (
 	"gorm.io/gorm"
 )
 
-type Role int
-
-const (
-	RoleAdmin Role = 1
-	RoleUser  Role = 2
-)
-
 type User struct {
 	ID        uint           `gorm:"primaryKey" json:"id"`
 	CreatedAt time.Time      `json:"created_at"`
@@ -23,6 +16,11 @@ type User struct {
 	Password string `gorm:"not null" json:"-"`
 	Role     Role   `gorm:"not null;default:2" json:"role"`
 
+	// FollowersCount and FollowingCount are only loaded when fetching a
+	// single user and left out everywhere else.
+	FollowersCount *int64 `gorm:"->;-:migration" json:"followers_count,omitempty"`
+	FollowingCount *int64 `gorm:"->;-:migration" json:"following_count,omitempty"`
+
 	Profile Profile `json:"profile,omitempty"`
 	Posts   []Post  `json:"posts,omitempty"`
 }
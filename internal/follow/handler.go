@@ -0,0 +1,100 @@
+package follow
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	pkgdb "post/internal/pkg/database"
+	"post/internal/pkg/response"
+	"post/internal/post"
+	"post/internal/user"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service}
+}
+
+func (h *Handler) FollowUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid ID", err.Error())
+		return
+	}
+
+	follow, err := h.service.Follow(c.MustGet("userID").(uint), uint(id))
+	if err != nil {
+		writeError(c, err, "Failed to follow user")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "User followed", follow)
+}
+
+func (h *Handler) UnfollowUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid ID", err.Error())
+		return
+	}
+
+	if err := h.service.Unfollow(c.MustGet("userID").(uint), uint(id)); err != nil {
+		writeError(c, err, "Failed to unfollow user")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "User unfollowed", nil)
+}
+
+func (h *Handler) GetFollowers(c *gin.Context) {
+	h.list(c, h.service.Followers, "Followers retrieved")
+}
+
+func (h *Handler) GetFollowing(c *gin.Context) {
+	h.list(c, h.service.Following, "Following retrieved")
+}
+
+func (h *Handler) list(c *gin.Context, list func(userID uint, query ListQuery) (*Page, error), message string) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid ID", err.Error())
+		return
+	}
+
+	var query ListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid query", err)
+		return
+	}
+
+	page, err := list(uint(id), query)
+	if err != nil {
+		writeError(c, err, "Failed to fetch users")
+		return
+	}
+
+	viewer := post.ViewerFrom(c)
+	response.Paginated(c, http.StatusOK, message, user.Views(page.Users, viewer.UserID, viewer.Role), response.Pagination{
+		Total:   &page.Total,
+		HasMore: page.HasMore,
+		Page:    page.Page,
+		PerPage: page.PerPage,
+	})
+}
+
+func writeError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, pkgdb.ErrRecordNotFound):
+		response.Error(c, http.StatusNotFound, "User not found", nil)
+	case errors.Is(err, ErrSelfFollow):
+		response.Error(c, http.StatusBadRequest, "Invalid input", err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, message, err.Error())
+	}
+}
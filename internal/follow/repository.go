@@ -0,0 +1,73 @@
+package follow
+
+import (
+	"post/internal/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository interface {
+	Create(follow *entity.Follow) error
+	Delete(followerID, followeeID uint) error
+	FindFollowers(userID uint, offset, limit int) ([]entity.User, error)
+	CountFollowers(userID uint) (int64, error)
+	FindFollowing(userID uint, offset, limit int) ([]entity.User, error)
+	CountFollowing(userID uint) (int64, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db}
+}
+
+// Create stores the follow, keeping the original one if it already exists.
+func (r *repository) Create(follow *entity.Follow) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(follow).Error
+}
+
+func (r *repository) Delete(followerID, followeeID uint) error {
+	return r.db.Where("follower_id = ? AND followee_id = ?", followerID, followeeID).Delete(&entity.Follow{}).Error
+}
+
+// followers scopes users to those following userID. Deleted users are left
+// out by the users model.
+func (r *repository) followers(userID uint) *gorm.DB {
+	return r.db.Model(&entity.User{}).
+		Joins("JOIN follows ON follows.follower_id = users.id AND follows.followee_id = ?", userID)
+}
+
+func (r *repository) following(userID uint) *gorm.DB {
+	return r.db.Model(&entity.User{}).
+		Joins("JOIN follows ON follows.followee_id = users.id AND follows.follower_id = ?", userID)
+}
+
+// FindFollowers returns the user's followers, most recent first.
+func (r *repository) FindFollowers(userID uint, offset, limit int) ([]entity.User, error) {
+	var users []entity.User
+	err := r.followers(userID).Preload("Profile").Order("follows.created_at DESC, users.id DESC").Offset(offset).Limit(limit).Find(&users).Error
+	return users, err
+}
+
+func (r *repository) CountFollowers(userID uint) (int64, error) {
+	var total int64
+	err := r.followers(userID).Count(&total).Error
+	return total, err
+}
+
+// FindFollowing returns the users userID follows, most recently followed
+// first.
+func (r *repository) FindFollowing(userID uint, offset, limit int) ([]entity.User, error) {
+	var users []entity.User
+	err := r.following(userID).Preload("Profile").Order("follows.created_at DESC, users.id DESC").Offset(offset).Limit(limit).Find(&users).Error
+	return users, err
+}
+
+func (r *repository) CountFollowing(userID uint) (int64, error) {
+	var total int64
+	err := r.following(userID).Count(&total).Error
+	return total, err
+}
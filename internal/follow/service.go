@@ -0,0 +1,102 @@
+package follow
+
+import (
+	"errors"
+
+	"post/internal/entity"
+	pkgdb "post/internal/pkg/database"
+	"post/internal/pkg/response"
+	"post/internal/user"
+)
+
+var ErrSelfFollow = errors.New("you cannot follow yourself")
+
+type Service interface {
+	Follow(followerID, followeeID uint) (*entity.Follow, error)
+	Unfollow(followerID, followeeID uint) error
+	Followers(userID uint, query ListQuery) (*Page, error)
+	Following(userID uint, query ListQuery) (*Page, error)
+}
+
+type service struct {
+	repo  Repository
+	users user.Service
+}
+
+func NewService(repo Repository, users user.Service) Service {
+	return &service{repo, users}
+}
+
+type ListQuery struct {
+	Page    int `form:"page" binding:"omitempty,min=1"`
+	PerPage int `form:"per_page" binding:"omitempty,min=1,max=100"`
+}
+
+// Page is one page of followers or followed users.
+type Page struct {
+	Users   []entity.User
+	Total   int64
+	HasMore bool
+	Page    int
+	PerPage int
+}
+
+// Follow makes followerID follow followeeID. Following someone again keeps
+// the original follow.
+func (s *service) Follow(followerID, followeeID uint) (*entity.Follow, error) {
+	if followerID == followeeID {
+		return nil, ErrSelfFollow
+	}
+	if _, err := s.users.GetByID(followeeID); err != nil {
+		return nil, pkgdb.ParseError(err)
+	}
+
+	follow := &entity.Follow{FollowerID: followerID, FolloweeID: followeeID}
+	if err := s.repo.Create(follow); err != nil {
+		return nil, err
+	}
+	return follow, nil
+}
+
+// Unfollow removes the follow if there is one.
+func (s *service) Unfollow(followerID, followeeID uint) error {
+	return s.repo.Delete(followerID, followeeID)
+}
+
+func (s *service) Followers(userID uint, query ListQuery) (*Page, error) {
+	return s.list(userID, query, s.repo.FindFollowers, s.repo.CountFollowers)
+}
+
+func (s *service) Following(userID uint, query ListQuery) (*Page, error) {
+	return s.list(userID, query, s.repo.FindFollowing, s.repo.CountFollowing)
+}
+
+func (s *service) list(
+	userID uint,
+	query ListQuery,
+	find func(userID uint, offset, limit int) ([]entity.User, error),
+	count func(userID uint) (int64, error),
+) (*Page, error) {
+	if _, err := s.users.GetByID(userID); err != nil {
+		return nil, pkgdb.ParseError(err)
+	}
+
+	page, perPage, offset := response.PageBounds(query.Page, query.PerPage)
+
+	users, err := find(userID, offset, perPage)
+	if err != nil {
+		return nil, err
+	}
+	total, err := count(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Page{
+		Users:   users,
+		Total:   total,
+		HasMore: int64(offset+len(users)) < total,
+		Page:    page,
+		PerPage: perPage,
+	}, nil
+}
@@ -0,0 +1,139 @@
+package follow_test
+
+import (
+	"testing"
+
+	"post/internal/entity"
+	"post/internal/follow"
+	pkgdb "post/internal/pkg/database"
+	"post/internal/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// MockRepository is a mock of follow.Repository
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) Create(f *entity.Follow) error {
+	args := m.Called(f)
+	return args.Error(0)
+}
+
+func (m *MockRepository) Delete(followerID, followeeID uint) error {
+	args := m.Called(followerID, followeeID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) FindFollowers(userID uint, offset, limit int) ([]entity.User, error) {
+	args := m.Called(userID, offset, limit)
+	return args.Get(0).([]entity.User), args.Error(1)
+}
+
+func (m *MockRepository) CountFollowers(userID uint) (int64, error) {
+	args := m.Called(userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRepository) FindFollowing(userID uint, offset, limit int) ([]entity.User, error) {
+	args := m.Called(userID, offset, limit)
+	return args.Get(0).([]entity.User), args.Error(1)
+}
+
+func (m *MockRepository) CountFollowing(userID uint) (int64, error) {
+	args := m.Called(userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// MockUserService mocks the part of user.Service follows rely on. Calling
+// any other method panics.
+type MockUserService struct {
+	mock.Mock
+	user.Service
+}
+
+func (m *MockUserService) GetByID(id uint) (*entity.User, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.User), args.Error(1)
+}
+
+func TestFollow(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockUsers := new(MockUserService)
+		service := follow.NewService(mockRepo, mockUsers)
+
+		mockUsers.On("GetByID", uint(2)).Return(&entity.User{ID: 2}, nil)
+		mockRepo.On("Create", &entity.Follow{FollowerID: 1, FolloweeID: 2}).Return(nil)
+
+		result, err := service.Follow(1, 2)
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(2), result.FolloweeID)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Self", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := follow.NewService(mockRepo, new(MockUserService))
+
+		_, err := service.Follow(1, 1)
+
+		assert.ErrorIs(t, err, follow.ErrSelfFollow)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("Unknown User", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockUsers := new(MockUserService)
+		service := follow.NewService(mockRepo, mockUsers)
+
+		mockUsers.On("GetByID", uint(2)).Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := service.Follow(1, 2)
+
+		assert.ErrorIs(t, err, pkgdb.ErrRecordNotFound)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+}
+
+func TestFollowers(t *testing.T) {
+	t.Run("Paginated", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockUsers := new(MockUserService)
+		service := follow.NewService(mockRepo, mockUsers)
+
+		mockUsers.On("GetByID", uint(1)).Return(&entity.User{ID: 1}, nil)
+		mockRepo.On("FindFollowers", uint(1), 10, 10).Return([]entity.User{{ID: 4}}, nil)
+		mockRepo.On("CountFollowers", uint(1)).Return(int64(11), nil)
+
+		result, err := service.Followers(1, follow.ListQuery{Page: 2, PerPage: 10})
+
+		assert.NoError(t, err)
+		assert.Len(t, result.Users, 1)
+		assert.Equal(t, int64(11), result.Total)
+		assert.False(t, result.HasMore)
+	})
+
+	t.Run("Following Defaults", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockUsers := new(MockUserService)
+		service := follow.NewService(mockRepo, mockUsers)
+
+		mockUsers.On("GetByID", uint(1)).Return(&entity.User{ID: 1}, nil)
+		mockRepo.On("FindFollowing", uint(1), 0, 20).Return([]entity.User{{ID: 2}, {ID: 3}}, nil)
+		mockRepo.On("CountFollowing", uint(1)).Return(int64(30), nil)
+
+		result, err := service.Following(1, follow.ListQuery{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 20, result.PerPage)
+		assert.True(t, result.HasMore)
+	})
+}
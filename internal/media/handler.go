@@ -0,0 +1,187 @@
+package media
+
+import (
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+
+	"post/internal/entity"
+	pkgdb "post/internal/pkg/database"
+	"post/internal/pkg/response"
+	"post/internal/post"
+
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead is allowed on top of the file size for the rest of the
+// multipart body.
+const multipartOverhead = 1 << 20
+
+type Handler struct {
+	service Service
+	maxSize int64
+}
+
+// NewHandler returns a media handler rejecting request bodies much larger
+// than maxSize before they are read.
+func NewHandler(service Service, maxSize int64) *Handler {
+	return &Handler{service, maxSize}
+}
+
+// UploadMedia stores the multipart "file" field.
+func (h *Handler) UploadMedia(c *gin.Context) {
+	input, file, ok := h.bindFile(c)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	media, err := h.service.Upload(c.Request.Context(), c.MustGet("userID").(uint), input)
+	if err != nil {
+		writeError(c, err, "Failed to upload file")
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "File uploaded", media)
+}
+
+// UploadPostImage stores the multipart "file" field as an image of the post.
+// It is processed in the background, so the response only acknowledges it.
+func (h *Handler) UploadPostImage(c *gin.Context) {
+	postID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid ID", err.Error())
+		return
+	}
+
+	input, file, ok := h.bindFile(c)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	media, err := h.service.UploadPostImage(c.Request.Context(), uint(postID), post.ViewerFrom(c), input)
+	if err != nil {
+		writeError(c, err, "Failed to upload image")
+		return
+	}
+
+	response.Success(c, http.StatusAccepted, "Image uploaded, processing", media)
+}
+
+func (h *Handler) GetPostImages(c *gin.Context) {
+	postID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid ID", err.Error())
+		return
+	}
+
+	images, err := h.service.ListPostImages(uint(postID), post.ViewerFrom(c))
+	if err != nil {
+		writeError(c, err, "Failed to fetch images")
+		return
+	}
+	if images == nil {
+		images = []entity.Media{}
+	}
+
+	response.Success(c, http.StatusOK, "Images retrieved", images)
+}
+
+// bindFile opens the multipart "file" field, writing an error response when
+// it is missing or the body is too large. The file must be closed.
+func (h *Handler) bindFile(c *gin.Context) (UploadInput, multipart.File, bool) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxSize+multipartOverhead)
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			response.Error(c, http.StatusRequestEntityTooLarge, "File too large", nil)
+			return UploadInput{}, nil, false
+		}
+		response.Error(c, http.StatusBadRequest, "Invalid input", err.Error())
+		return UploadInput{}, nil, false
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid input", err.Error())
+		return UploadInput{}, nil, false
+	}
+	return UploadInput{Filename: header.Filename, Size: header.Size, Body: file}, file, true
+}
+
+// GetMyMedia lists the caller's uploads.
+func (h *Handler) GetMyMedia(c *gin.Context) {
+	var query ListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid query", err)
+		return
+	}
+
+	page, err := h.service.List(c.MustGet("userID").(uint), query)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to fetch files", err.Error())
+		return
+	}
+
+	media := page.Media
+	if media == nil {
+		media = []entity.Media{}
+	}
+	response.Paginated(c, http.StatusOK, "Files retrieved", media, response.Pagination{
+		Total:   &page.Total,
+		HasMore: page.HasMore,
+		Page:    page.Page,
+		PerPage: page.PerPage,
+	})
+}
+
+func (h *Handler) GetMedia(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid ID", err.Error())
+		return
+	}
+
+	media, err := h.service.GetByID(uint(id), post.ViewerFrom(c))
+	if err != nil {
+		writeError(c, err, "Failed to fetch file")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "File retrieved", media)
+}
+
+func (h *Handler) DeleteMedia(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid ID", err.Error())
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), uint(id), post.ViewerFrom(c)); err != nil {
+		writeError(c, err, "Failed to delete file")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "File deleted", nil)
+}
+
+func writeError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, pkgdb.ErrRecordNotFound):
+		response.Error(c, http.StatusNotFound, "Not found", nil)
+	case errors.Is(err, ErrForbidden):
+		response.Error(c, http.StatusForbidden, "Forbidden", err.Error())
+	case errors.Is(err, ErrTooLarge):
+		response.Error(c, http.StatusRequestEntityTooLarge, "File too large", err.Error())
+	case errors.Is(err, ErrUnsupportedType):
+		response.Error(c, http.StatusUnsupportedMediaType, "Unsupported file type", err.Error())
+	case errors.Is(err, ErrQuotaExceeded):
+		response.Error(c, http.StatusForbidden, "Quota exceeded", err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, message, err.Error())
+	}
+}
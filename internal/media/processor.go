@@ -0,0 +1,57 @@
+package media
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// batchSize is how many images a processor claims at a time.
+const batchSize = 10
+
+// Processor periodically processes pending post images, so uploads return
+// without waiting for resizing. Any number of processors can run side by
+// side, each image is claimed by one of them.
+type Processor struct {
+	service  Service
+	interval time.Duration
+}
+
+func NewProcessor(service Service, interval time.Duration) *Processor {
+	return &Processor{service, interval}
+}
+
+// Run blocks until ctx is cancelled.
+func (p *Processor) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.tick(ctx)
+		}
+	}
+}
+
+// tick drains the pending images a batch at a time.
+func (p *Processor) tick(ctx context.Context) {
+	for ctx.Err() == nil {
+		count, err := p.service.ProcessPending(ctx, batchSize)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Error().Err(err).Msg("Failed to process images")
+			}
+			return
+		}
+		if count > 0 {
+			log.Info().Int("count", count).Msg("Processed images")
+		}
+		if count < batchSize {
+			return
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package media
+
+import (
+	"time"
+
+	"post/internal/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository interface {
+	CreateWithinQuota(media *entity.Media, quota int64) error
+	FindByID(id uint) (*entity.Media, error)
+	FindByUserID(userID uint, offset, limit int) ([]entity.Media, error)
+	FindReadyByPostID(postID uint) ([]entity.Media, error)
+	CountByUserID(userID uint) (int64, error)
+	UsedBytes(userID uint) (int64, error)
+	Delete(id uint) error
+	ClaimPending(staleBefore time.Time, limit int) ([]uint, error)
+	SaveProcessed(media *entity.Media) (bool, error)
+	MarkFailed(id uint) (bool, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db}
+}
+
+// CreateWithinQuota stores the media unless it would take its owner past
+// quota bytes, in which case ErrQuotaExceeded is returned.
+func (r *repository) CreateWithinQuota(media *entity.Media, quota int64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the owner so concurrent uploads are counted one at a time
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&entity.User{}, media.UserID).Error; err != nil {
+			return err
+		}
+		used, err := usedBytes(tx, media.UserID)
+		if err != nil {
+			return err
+		}
+		if used+media.Size > quota {
+			return ErrQuotaExceeded
+		}
+		return tx.Create(media).Error
+	})
+}
+
+func (r *repository) FindByID(id uint) (*entity.Media, error) {
+	var media entity.Media
+	err := r.db.Preload("Variants").First(&media, id).Error
+	return &media, err
+}
+
+// FindByUserID returns the user's uploads, newest first.
+func (r *repository) FindByUserID(userID uint, offset, limit int) ([]entity.Media, error) {
+	var media []entity.Media
+	err := r.db.Preload("Variants").Where("user_id = ?", userID).Order("id DESC").Offset(offset).Limit(limit).Find(&media).Error
+	return media, err
+}
+
+// FindReadyByPostID returns the post's processed images, oldest first.
+func (r *repository) FindReadyByPostID(postID uint) ([]entity.Media, error) {
+	var media []entity.Media
+	err := r.db.Preload("Variants").
+		Where("post_id = ? AND status = ?", postID, entity.MediaStatusReady).
+		Order("id").
+		Find(&media).Error
+	return media, err
+}
+
+func (r *repository) CountByUserID(userID uint) (int64, error) {
+	var total int64
+	err := r.db.Model(&entity.Media{}).Where("user_id = ?", userID).Count(&total).Error
+	return total, err
+}
+
+func (r *repository) UsedBytes(userID uint) (int64, error) {
+	return usedBytes(r.db, userID)
+}
+
+func (r *repository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("media_id = ?", id).Delete(&entity.MediaVariant{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&entity.Media{}, id).Error
+	})
+}
+
+// ClaimPending marks up to limit pending images as processing and returns
+// their IDs. Images stuck in processing since before staleBefore, e.g.
+// because their worker died, are claimed again. Rows locked by another
+// worker are skipped, so no image is processed twice at once.
+func (r *repository) ClaimPending(staleBefore time.Time, limit int) ([]uint, error) {
+	var ids []uint
+	err := r.db.Raw(`UPDATE media
+		SET status = ?, updated_at = ?
+		WHERE id IN (
+			SELECT id FROM media
+			WHERE status = ? OR (status = ? AND updated_at < ?)
+			ORDER BY id
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id`,
+		entity.MediaStatusProcessing, time.Now(), entity.MediaStatusPending, entity.MediaStatusProcessing, staleBefore, limit,
+	).Scan(&ids).Error
+	return ids, err
+}
+
+// SaveProcessed stores a processed image, replacing any variants it had. It
+// changes nothing and reports false when the image is no longer being
+// processed, because its owner deleted it or another worker took it over.
+func (r *repository) SaveProcessed(media *entity.Media) (bool, error) {
+	saved := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&entity.Media{}).
+			Where("id = ? AND status = ?", media.ID, entity.MediaStatusProcessing).
+			Updates(map[string]any{
+				"key":          media.Key,
+				"url":          media.URL,
+				"content_type": media.ContentType,
+				"size":         media.Size,
+				"width":        media.Width,
+				"height":       media.Height,
+				"blurhash":     media.Blurhash,
+				"status":       media.Status,
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		if err := tx.Where("media_id = ?", media.ID).Delete(&entity.MediaVariant{}).Error; err != nil {
+			return err
+		}
+		if len(media.Variants) > 0 {
+			for i := range media.Variants {
+				media.Variants[i].ID = 0
+				media.Variants[i].MediaID = media.ID
+			}
+			if err := tx.Create(&media.Variants).Error; err != nil {
+				return err
+			}
+		}
+		saved = true
+		return nil
+	})
+	return saved, err
+}
+
+// MarkFailed gives up on an image being processed. It reports false when the
+// image was deleted or finished by another worker in the meantime.
+func (r *repository) MarkFailed(id uint) (bool, error) {
+	result := r.db.Model(&entity.Media{}).
+		Where("id = ? AND status = ?", id, entity.MediaStatusProcessing).
+		Update("status", entity.MediaStatusFailed)
+	return result.RowsAffected == 1, result.Error
+}
+
+// usedBytes counts the user's uploads along with the variants rendered from
+// them.
+func usedBytes(db *gorm.DB, userID uint) (int64, error) {
+	var used int64
+	err := db.Raw(`SELECT
+		(SELECT COALESCE(SUM(size), 0) FROM media WHERE user_id = ?) +
+		(SELECT COALESCE(SUM(media_variants.size), 0) FROM media_variants
+			JOIN media ON media.id = media_variants.media_id
+			WHERE media.user_id = ?)`,
+		userID, userID,
+	).Scan(&used).Error
+	return used, err
+}
@@ -0,0 +1,416 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"post/internal/entity"
+	pkgdb "post/internal/pkg/database"
+	"post/internal/pkg/imageproc"
+	"post/internal/pkg/response"
+	"post/internal/pkg/storage"
+	"post/internal/post"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+var (
+	ErrForbidden       = errors.New("you are not allowed to modify this file")
+	ErrTooLarge        = errors.New("file is too large")
+	ErrUnsupportedType = errors.New("file type is not supported")
+	ErrQuotaExceeded   = errors.New("upload quota exceeded")
+
+	// errNotProcessing is returned when an image was deleted or taken over
+	// by another worker while it was processed.
+	errNotProcessing = errors.New("image is no longer being processed")
+)
+
+// allowedTypes maps the content types accepted for upload to the extension
+// files are stored with. SVG and HTML are left out as they can carry scripts.
+var allowedTypes = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+	"video/mp4":       ".mp4",
+	"video/webm":      ".webm",
+	"audio/mpeg":      ".mp3",
+}
+
+// imageTypes are the uploads accepted as post images.
+var imageTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// sniffLength is how much of a file is read to detect its type.
+const sniffLength = 3072
+
+const (
+	// processingTimeout is how long an image may stay in processing before
+	// another worker takes it over.
+	processingTimeout = 10 * time.Minute
+	// maxPixels is the largest image, in pixels, that is decoded.
+	maxPixels = 50_000_000
+)
+
+type Service interface {
+	Upload(ctx context.Context, userID uint, input UploadInput) (*entity.Media, error)
+	UploadPostImage(ctx context.Context, postID uint, viewer post.Viewer, input UploadInput) (*entity.Media, error)
+	List(userID uint, query ListQuery) (*Page, error)
+	ListPostImages(postID uint, viewer post.Viewer) ([]entity.Media, error)
+	GetByID(id uint, viewer post.Viewer) (*entity.Media, error)
+	Delete(ctx context.Context, id uint, viewer post.Viewer) error
+	ProcessPending(ctx context.Context, limit int) (int, error)
+}
+
+type service struct {
+	repo    Repository
+	storage storage.Storage
+	posts   post.Service
+	maxSize int64
+	quota   int64
+	images  imageproc.Options
+}
+
+// NewService returns a media service. Uploads are limited to maxSize bytes
+// each and quota bytes in total per user. Post images are processed with
+// the given options.
+func NewService(repo Repository, storage storage.Storage, posts post.Service, maxSize, quota int64, images imageproc.Options) Service {
+	if images.MaxPixels == 0 {
+		images.MaxPixels = maxPixels
+	}
+	return &service{repo, storage, posts, maxSize, quota, images}
+}
+
+// UploadInput is a file received from a client. Size must be the exact
+// length of Body.
+type UploadInput struct {
+	Filename string
+	Size     int64
+	Body     io.Reader
+}
+
+type ListQuery struct {
+	Page    int `form:"page" binding:"omitempty,min=1"`
+	PerPage int `form:"per_page" binding:"omitempty,min=1,max=100"`
+}
+
+// Page is one page of a user's uploads.
+type Page struct {
+	Media   []entity.Media
+	Total   int64
+	HasMore bool
+	Page    int
+	PerPage int
+}
+
+// Upload stores the file after checking its size, its actual type as
+// sniffed from the content, and the owner's quota.
+func (s *service) Upload(ctx context.Context, userID uint, input UploadInput) (*entity.Media, error) {
+	media := &entity.Media{UserID: userID, Status: entity.MediaStatusReady}
+	if err := s.store(ctx, media, input, allowedTypes); err != nil {
+		return nil, err
+	}
+	return media, nil
+}
+
+// UploadPostImage stores an image for a post the viewer can manage. It is
+// returned pending, without a URL, until the processor has stripped its
+// metadata and rendered its thumbnails.
+func (s *service) UploadPostImage(ctx context.Context, postID uint, viewer post.Viewer, input UploadInput) (*entity.Media, error) {
+	p, err := s.posts.GetByID(postID, viewer)
+	if err != nil {
+		return nil, err
+	}
+	if !viewer.CanManage(p) {
+		return nil, ErrForbidden
+	}
+
+	media := &entity.Media{UserID: viewer.UserID, PostID: &postID, Status: entity.MediaStatusPending}
+	if err := s.store(ctx, media, input, imageTypes); err != nil {
+		return nil, err
+	}
+	return media, nil
+}
+
+// store saves the upload and its record. Only ready media get a URL, so
+// unprocessed originals are never linked.
+func (s *service) store(ctx context.Context, media *entity.Media, input UploadInput, types map[string]string) error {
+	if input.Size > s.maxSize {
+		return fmt.Errorf("%w: the limit is %d bytes", ErrTooLarge, s.maxSize)
+	}
+
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(input.Body, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return err
+	}
+	head = head[:n]
+	contentType, _, _ := strings.Cut(mimetype.Detect(head).String(), ";")
+	ext, ok := types[contentType]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnsupportedType, contentType)
+	}
+
+	used, err := s.repo.UsedBytes(media.UserID)
+	if err != nil {
+		return err
+	}
+	if used+input.Size > s.quota {
+		return ErrQuotaExceeded
+	}
+
+	key, err := newKey(media.UserID, ext)
+	if err != nil {
+		return err
+	}
+	body := io.MultiReader(bytes.NewReader(head), input.Body)
+	if err := s.storage.Put(ctx, key, body, input.Size, contentType); err != nil {
+		return err
+	}
+
+	media.Key = key
+	media.Filename = filepath.Base(input.Filename)
+	media.ContentType = contentType
+	media.Size = input.Size
+	if media.Status == entity.MediaStatusReady {
+		media.URL = s.storage.URL(key)
+	}
+	if err := s.repo.CreateWithinQuota(media, s.quota); err != nil {
+		// Another upload may have used up the quota in the meantime
+		s.deleteObjects(context.WithoutCancel(ctx), key)
+		return err
+	}
+	return nil
+}
+
+func (s *service) List(userID uint, query ListQuery) (*Page, error) {
+	page, perPage, offset := response.PageBounds(query.Page, query.PerPage)
+
+	media, err := s.repo.FindByUserID(userID, offset, perPage)
+	if err != nil {
+		return nil, err
+	}
+	total, err := s.repo.CountByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Page{
+		Media:   media,
+		Total:   total,
+		HasMore: int64(offset+len(media)) < total,
+		Page:    page,
+		PerPage: perPage,
+	}, nil
+}
+
+// ListPostImages returns the processed images of a post the viewer can see.
+func (s *service) ListPostImages(postID uint, viewer post.Viewer) ([]entity.Media, error) {
+	if _, err := s.posts.GetByID(postID, viewer); err != nil {
+		return nil, err
+	}
+	return s.repo.FindReadyByPostID(postID)
+}
+
+// GetByID returns a file the viewer may see: their own, any file for
+// moderators who may remove it, and the processed images of posts the
+// viewer can see. Other files are reported as not found.
+func (s *service) GetByID(id uint, viewer post.Viewer) (*entity.Media, error) {
+	media, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, pkgdb.ParseError(err)
+	}
+	if (viewer.UserID != 0 && media.UserID == viewer.UserID) || viewer.Can(entity.PermMediaDeleteAny) {
+		return media, nil
+	}
+	if media.PostID == nil || media.Status != entity.MediaStatusReady {
+		return nil, pkgdb.ErrRecordNotFound
+	}
+	if _, err := s.posts.GetByID(*media.PostID, viewer); err != nil {
+		return nil, err
+	}
+	return media, nil
+}
+
+// Delete removes the file on behalf of its owner or a moderator. The record goes
+// first so a failure never leaves it pointing at a missing file.
+func (s *service) Delete(ctx context.Context, id uint, viewer post.Viewer) error {
+	media, err := s.repo.FindByID(id)
+	if err != nil {
+		return pkgdb.ParseError(err)
+	}
+	if viewer.UserID == 0 || (media.UserID != viewer.UserID && !viewer.Can(entity.PermMediaDeleteAny)) {
+		return ErrForbidden
+	}
+
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	for _, variant := range media.Variants {
+		if err := s.storage.Delete(ctx, variant.Key); err != nil {
+			return err
+		}
+	}
+	return s.storage.Delete(ctx, media.Key)
+}
+
+// ProcessPending processes up to limit pending post images and returns how
+// many became ready. Images that cannot be processed are marked failed.
+func (s *service) ProcessPending(ctx context.Context, limit int) (int, error) {
+	ids, err := s.repo.ClaimPending(time.Now().Add(-processingTimeout), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	processed := 0
+	for _, id := range ids {
+		media, err := s.repo.FindByID(id)
+		if err != nil {
+			// The claim runs out after processingTimeout and the image is
+			// tried again, unless it was deleted
+			log.Printf("Failed to load image %d: %v", id, err)
+			continue
+		}
+		original := media.Key
+		if err := s.process(ctx, media); err != nil {
+			if ctx.Err() != nil {
+				// Shutting down: leave the image to be claimed again
+				return processed, ctx.Err()
+			}
+			if errors.Is(err, errNotProcessing) {
+				log.Printf("Discarded image %d: %v", id, err)
+				continue
+			}
+			log.Printf("Failed to process image %d: %v", id, err)
+			s.fail(context.WithoutCancel(ctx), id, original)
+			continue
+		}
+		processed++
+	}
+	return processed, nil
+}
+
+// fail marks the image failed and deletes its original, which still carries
+// the metadata processing would have stripped.
+func (s *service) fail(ctx context.Context, id uint, original string) {
+	failed, err := s.repo.MarkFailed(id)
+	if err != nil {
+		log.Printf("Failed to mark image %d as failed: %v", id, err)
+		return
+	}
+	if failed {
+		s.deleteObjects(ctx, original)
+	}
+}
+
+// process re-encodes an image under a new key, renders its thumbnails and
+// removes the original, which may still carry EXIF and GPS metadata.
+func (s *service) process(ctx context.Context, media *entity.Media) error {
+	r, err := s.storage.Open(ctx, media.Key)
+	if err != nil {
+		return err
+	}
+	result, err := imageproc.Process(r, s.images)
+	r.Close()
+	if err != nil {
+		return err
+	}
+
+	base, err := newKey(media.UserID, "")
+	if err != nil {
+		return err
+	}
+	var written []string
+	put := func(key string, img imageproc.Image) error {
+		if err := s.storage.Put(ctx, key, bytes.NewReader(img.Data), int64(len(img.Data)), img.ContentType); err != nil {
+			return err
+		}
+		written = append(written, key)
+		return nil
+	}
+
+	key := base + result.Ext
+	if err := put(key, result.Image); err != nil {
+		s.deleteObjects(context.WithoutCancel(ctx), written...)
+		return err
+	}
+	variants := make([]entity.MediaVariant, 0, len(result.Variants))
+	for _, v := range result.Variants {
+		variantKey := base + "-" + v.Name + v.Ext
+		if err := put(variantKey, v.Image); err != nil {
+			s.deleteObjects(context.WithoutCancel(ctx), written...)
+			return err
+		}
+		variants = append(variants, entity.MediaVariant{
+			MediaID:     media.ID,
+			Name:        v.Name,
+			Key:         variantKey,
+			URL:         s.storage.URL(variantKey),
+			ContentType: v.ContentType,
+			Width:       v.Width,
+			Height:      v.Height,
+			Size:        int64(len(v.Data)),
+		})
+	}
+
+	stale := []string{media.Key}
+	for _, v := range media.Variants {
+		stale = append(stale, v.Key)
+	}
+
+	media.Key = key
+	media.URL = s.storage.URL(key)
+	media.ContentType = result.ContentType
+	media.Size = int64(len(result.Data))
+	media.Width = result.Width
+	media.Height = result.Height
+	media.Blurhash = result.Blurhash
+	media.Status = entity.MediaStatusReady
+	media.Variants = variants
+	saved, err := s.repo.SaveProcessed(media)
+	if err != nil {
+		s.deleteObjects(context.WithoutCancel(ctx), written...)
+		return err
+	}
+	if !saved {
+		// Whoever deleted or took over the image cleans up its original
+		s.deleteObjects(context.WithoutCancel(ctx), written...)
+		return errNotProcessing
+	}
+
+	s.deleteObjects(context.WithoutCancel(ctx), stale...)
+	return nil
+}
+
+// deleteObjects removes files that are no longer referenced, logging
+// failures as there is nothing else to do about them.
+func (s *service) deleteObjects(ctx context.Context, keys ...string) {
+	for _, key := range keys {
+		if err := s.storage.Delete(ctx, key); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			log.Printf("Failed to delete orphaned file %s: %v", key, err)
+		}
+	}
+}
+
+// newKey returns a random, unguessable storage key under the user's prefix.
+func newKey(userID uint, ext string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d/%s%s", userID, hex.EncodeToString(b), ext), nil
+}
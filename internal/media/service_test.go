@@ -0,0 +1,489 @@
+package media_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"post/internal/entity"
+	"post/internal/media"
+	pkgdb "post/internal/pkg/database"
+	"post/internal/pkg/imageproc"
+	"post/internal/post"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockRepository is a mock of media.Repository
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) CreateWithinQuota(media *entity.Media, quota int64) error {
+	args := m.Called(media, quota)
+	return args.Error(0)
+}
+
+func (m *MockRepository) FindByID(id uint) (*entity.Media, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Media), args.Error(1)
+}
+
+func (m *MockRepository) FindByUserID(userID uint, offset, limit int) ([]entity.Media, error) {
+	args := m.Called(userID, offset, limit)
+	return args.Get(0).([]entity.Media), args.Error(1)
+}
+
+func (m *MockRepository) CountByUserID(userID uint) (int64, error) {
+	args := m.Called(userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRepository) UsedBytes(userID uint) (int64, error) {
+	args := m.Called(userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRepository) FindReadyByPostID(postID uint) ([]entity.Media, error) {
+	args := m.Called(postID)
+	return args.Get(0).([]entity.Media), args.Error(1)
+}
+
+func (m *MockRepository) Delete(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockRepository) ClaimPending(staleBefore time.Time, limit int) ([]uint, error) {
+	args := m.Called(limit)
+	return args.Get(0).([]uint), args.Error(1)
+}
+
+func (m *MockRepository) SaveProcessed(media *entity.Media) (bool, error) {
+	args := m.Called(media)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) MarkFailed(id uint) (bool, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
+// MockStorage is a mock of storage.Storage that records what was written.
+type MockStorage struct {
+	mock.Mock
+	written []byte
+}
+
+func (m *MockStorage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	m.written, _ = io.ReadAll(r)
+	args := m.Called(key, size, contentType)
+	return args.Error(0)
+}
+
+func (m *MockStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	args := m.Called(key)
+	return args.Get(0).(io.ReadCloser), args.Error(1)
+}
+
+func (m *MockStorage) Delete(ctx context.Context, key string) error {
+	args := m.Called(key)
+	return args.Error(0)
+}
+
+func (m *MockStorage) URL(key string) string {
+	return "https://cdn.example.com/" + key
+}
+
+// MockPostService mocks the part of post.Service media rely on. Calling any
+// other method panics.
+type MockPostService struct {
+	mock.Mock
+	post.Service
+}
+
+func (m *MockPostService) GetByID(id uint, viewer post.Viewer) (*entity.Post, error) {
+	args := m.Called(id, viewer)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Post), args.Error(1)
+}
+
+// png is a PNG signature followed by enough bytes to span the sniffed prefix.
+var png = append([]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), bytes.Repeat([]byte{0}, 4000)...)
+
+func upload(data []byte) media.UploadInput {
+	return media.UploadInput{Filename: "../photo.png", Size: int64(len(data)), Body: bytes.NewReader(data)}
+}
+
+func TestUpload(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockStorage := new(MockStorage)
+		service := media.NewService(mockRepo, mockStorage, new(MockPostService), 10000, 100000, imageproc.Options{})
+
+		mockRepo.On("UsedBytes", uint(3)).Return(int64(0), nil)
+		mockStorage.On("Put", mock.MatchedBy(func(key string) bool {
+			return strings.HasPrefix(key, "3/") && strings.HasSuffix(key, ".png")
+		}), int64(len(png)), "image/png").Return(nil)
+		mockRepo.On("CreateWithinQuota", mock.AnythingOfType("*entity.Media"), int64(100000)).Return(nil)
+
+		result, err := service.Upload(context.Background(), 3, upload(png))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "image/png", result.ContentType)
+		assert.Equal(t, "photo.png", result.Filename)
+		assert.Equal(t, "https://cdn.example.com/"+result.Key, result.URL)
+		assert.Equal(t, png, mockStorage.written, "sniffed bytes must still be stored")
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Unsupported Type", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockStorage := new(MockStorage)
+		service := media.NewService(mockRepo, mockStorage, new(MockPostService), 10000, 100000, imageproc.Options{})
+
+		_, err := service.Upload(context.Background(), 3, upload([]byte("<svg xmlns=\"http://www.w3.org/2000/svg\"><script/></svg>")))
+
+		assert.ErrorIs(t, err, media.ErrUnsupportedType)
+		mockStorage.AssertNotCalled(t, "Put", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Too Large", func(t *testing.T) {
+		service := media.NewService(new(MockRepository), new(MockStorage), new(MockPostService), 100, 100000, imageproc.Options{})
+
+		_, err := service.Upload(context.Background(), 3, upload(png))
+
+		assert.ErrorIs(t, err, media.ErrTooLarge)
+	})
+
+	t.Run("Over Quota", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockStorage := new(MockStorage)
+		service := media.NewService(mockRepo, mockStorage, new(MockPostService), 10000, 5000, imageproc.Options{})
+
+		mockRepo.On("UsedBytes", uint(3)).Return(int64(2000), nil)
+
+		_, err := service.Upload(context.Background(), 3, upload(png))
+
+		assert.ErrorIs(t, err, media.ErrQuotaExceeded)
+		mockStorage.AssertNotCalled(t, "Put", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Quota Used Up Concurrently", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockStorage := new(MockStorage)
+		service := media.NewService(mockRepo, mockStorage, new(MockPostService), 10000, 100000, imageproc.Options{})
+
+		mockRepo.On("UsedBytes", uint(3)).Return(int64(0), nil)
+		mockStorage.On("Put", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		mockRepo.On("CreateWithinQuota", mock.Anything, mock.Anything).Return(media.ErrQuotaExceeded)
+		mockStorage.On("Delete", mock.Anything).Return(nil)
+
+		_, err := service.Upload(context.Background(), 3, upload(png))
+
+		assert.ErrorIs(t, err, media.ErrQuotaExceeded)
+		mockStorage.AssertCalled(t, "Delete", mock.Anything)
+	})
+}
+
+func TestGetByID(t *testing.T) {
+	postID := uint(1)
+	image := func() *entity.Media {
+		return &entity.Media{ID: 1, UserID: 3, PostID: &postID, Status: entity.MediaStatusReady}
+	}
+	setup := func(m *entity.Media) (*MockPostService, media.Service) {
+		mockRepo := new(MockRepository)
+		mockPosts := new(MockPostService)
+		mockRepo.On("FindByID", uint(1)).Return(m, nil)
+		return mockPosts, media.NewService(mockRepo, new(MockStorage), mockPosts, 10000, 100000, imageproc.Options{})
+	}
+
+	t.Run("Owner", func(t *testing.T) {
+		own := &entity.Media{ID: 1, UserID: 3, Status: entity.MediaStatusReady}
+		mockPosts, service := setup(own)
+
+		result, err := service.GetByID(1, post.Viewer{UserID: 3, Role: entity.RoleUser})
+
+		assert.NoError(t, err)
+		assert.Equal(t, own, result)
+		mockPosts.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Moderator", func(t *testing.T) {
+		_, service := setup(&entity.Media{ID: 1, UserID: 3, Status: entity.MediaStatusReady})
+
+		_, err := service.GetByID(1, post.Viewer{UserID: 4, Role: entity.RoleEditor})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("OtherUsersFile", func(t *testing.T) {
+		_, service := setup(&entity.Media{ID: 1, UserID: 3, Status: entity.MediaStatusReady})
+
+		_, err := service.GetByID(1, post.Viewer{UserID: 4, Role: entity.RoleUser})
+
+		assert.ErrorIs(t, err, pkgdb.ErrRecordNotFound)
+	})
+
+	t.Run("VisiblePostImage", func(t *testing.T) {
+		reader := post.Viewer{UserID: 4, Role: entity.RoleUser}
+		mockPosts, service := setup(image())
+		mockPosts.On("GetByID", uint(1), reader).Return(&entity.Post{ID: 1, UserID: 3, Status: entity.PostStatusPublished}, nil)
+
+		_, err := service.GetByID(1, reader)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("DraftPostImage", func(t *testing.T) {
+		reader := post.Viewer{UserID: 4, Role: entity.RoleUser}
+		mockPosts, service := setup(image())
+		mockPosts.On("GetByID", uint(1), reader).Return(nil, pkgdb.ErrRecordNotFound)
+
+		_, err := service.GetByID(1, reader)
+
+		assert.ErrorIs(t, err, pkgdb.ErrRecordNotFound)
+	})
+
+	t.Run("PendingPostImage", func(t *testing.T) {
+		pending := image()
+		pending.Status = entity.MediaStatusPending
+		mockPosts, service := setup(pending)
+
+		_, err := service.GetByID(1, post.Viewer{UserID: 4, Role: entity.RoleUser})
+
+		assert.ErrorIs(t, err, pkgdb.ErrRecordNotFound)
+		mockPosts.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+	})
+}
+
+func TestDelete(t *testing.T) {
+	t.Run("Owner", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockStorage := new(MockStorage)
+		service := media.NewService(mockRepo, mockStorage, new(MockPostService), 10000, 100000, imageproc.Options{})
+
+		mockRepo.On("FindByID", uint(1)).Return(&entity.Media{ID: 1, UserID: 3, Key: "3/a.png"}, nil)
+		mockRepo.On("Delete", uint(1)).Return(nil)
+		mockStorage.On("Delete", "3/a.png").Return(nil)
+
+		err := service.Delete(context.Background(), 1, post.Viewer{UserID: 3, Role: entity.RoleUser})
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		mockStorage.AssertExpectations(t)
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockStorage := new(MockStorage)
+		service := media.NewService(mockRepo, mockStorage, new(MockPostService), 10000, 100000, imageproc.Options{})
+
+		mockRepo.On("FindByID", uint(1)).Return(&entity.Media{ID: 1, UserID: 3, Key: "3/a.png"}, nil)
+
+		err := service.Delete(context.Background(), 1, post.Viewer{UserID: 4, Role: entity.RoleUser})
+
+		assert.ErrorIs(t, err, media.ErrForbidden)
+		mockRepo.AssertNotCalled(t, "Delete", uint(1))
+		mockStorage.AssertNotCalled(t, "Delete", mock.Anything)
+	})
+}
+
+func TestUploadPostImage(t *testing.T) {
+	author := post.Viewer{UserID: 3, Role: entity.RoleUser}
+
+	t.Run("Pending", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockStorage := new(MockStorage)
+		mockPosts := new(MockPostService)
+		service := media.NewService(mockRepo, mockStorage, mockPosts, 10000, 100000, imageproc.Options{})
+
+		mockPosts.On("GetByID", uint(1), author).Return(&entity.Post{ID: 1, UserID: 3}, nil)
+		mockRepo.On("UsedBytes", uint(3)).Return(int64(0), nil)
+		mockStorage.On("Put", mock.Anything, int64(len(png)), "image/png").Return(nil)
+		mockRepo.On("CreateWithinQuota", mock.MatchedBy(func(m *entity.Media) bool {
+			return *m.PostID == 1 && m.Status == entity.MediaStatusPending
+		}), int64(100000)).Return(nil)
+
+		result, err := service.UploadPostImage(context.Background(), 1, author, upload(png))
+
+		assert.NoError(t, err)
+		assert.Empty(t, result.URL, "unprocessed images must not be linked")
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Not An Image", func(t *testing.T) {
+		mockStorage := new(MockStorage)
+		mockPosts := new(MockPostService)
+		service := media.NewService(new(MockRepository), mockStorage, mockPosts, 10000, 100000, imageproc.Options{})
+
+		mockPosts.On("GetByID", uint(1), author).Return(&entity.Post{ID: 1, UserID: 3}, nil)
+
+		_, err := service.UploadPostImage(context.Background(), 1, author, upload([]byte("%PDF-1.7\n")))
+
+		assert.ErrorIs(t, err, media.ErrUnsupportedType)
+		mockStorage.AssertNotCalled(t, "Put", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Not The Author", func(t *testing.T) {
+		mockStorage := new(MockStorage)
+		mockPosts := new(MockPostService)
+		service := media.NewService(new(MockRepository), mockStorage, mockPosts, 10000, 100000, imageproc.Options{})
+		other := post.Viewer{UserID: 4, Role: entity.RoleUser}
+
+		mockPosts.On("GetByID", uint(1), other).Return(&entity.Post{ID: 1, UserID: 3, Status: entity.PostStatusPublished}, nil)
+
+		_, err := service.UploadPostImage(context.Background(), 1, other, upload(png))
+
+		assert.ErrorIs(t, err, media.ErrForbidden)
+		mockStorage.AssertNotCalled(t, "Put", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Hidden Post", func(t *testing.T) {
+		mockPosts := new(MockPostService)
+		service := media.NewService(new(MockRepository), new(MockStorage), mockPosts, 10000, 100000, imageproc.Options{})
+
+		mockPosts.On("GetByID", uint(1), author).Return(nil, pkgdb.ErrRecordNotFound)
+
+		_, err := service.UploadPostImage(context.Background(), 1, author, upload(png))
+
+		assert.ErrorIs(t, err, pkgdb.ErrRecordNotFound)
+	})
+}
+
+// photo returns a JPEG carrying an EXIF segment.
+func photo(t *testing.T, width, height int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{uint8(x), uint8(y), 128, 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	exif := append([]byte{0xFF, 0xE1, 0x00, 0x0E}, "Exif\x00\x00GPS:51N"...)
+	return append(append([]byte{0xFF, 0xD8}, exif...), buf.Bytes()[2:]...)
+}
+
+func TestProcessPending(t *testing.T) {
+	options := imageproc.Options{MaxWidth: 200, Widths: []int{50, 100, 400}, Quality: 80}
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockStorage := new(MockStorage)
+		service := media.NewService(mockRepo, mockStorage, new(MockPostService), 10000, 100000, options)
+
+		mockRepo.On("ClaimPending", 10).Return([]uint{1}, nil)
+		mockRepo.On("FindByID", uint(1)).Return(&entity.Media{ID: 1, UserID: 3, Key: "3/orig.jpg", Status: entity.MediaStatusProcessing}, nil)
+		mockStorage.On("Open", "3/orig.jpg").Return(io.NopCloser(bytes.NewReader(photo(t, 400, 300))), nil)
+		mockStorage.On("Put", mock.Anything, mock.Anything, "image/jpeg").Return(nil)
+		mockRepo.On("SaveProcessed", mock.MatchedBy(func(m *entity.Media) bool {
+			return m.Status == entity.MediaStatusReady && m.Key != "3/orig.jpg" && m.URL != "" &&
+				m.Width == 200 && m.Height == 150 && len(m.Blurhash) == 28 &&
+				len(m.Variants) == 2 && m.Variants[0].Name == "50w" && m.Variants[1].Width == 100
+		})).Return(true, nil)
+		mockStorage.On("Delete", "3/orig.jpg").Return(nil)
+
+		count, err := service.ProcessPending(context.Background(), 10)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+		assert.NotContains(t, string(mockStorage.written), "Exif")
+		mockStorage.AssertNumberOfCalls(t, "Put", 3)
+		mockRepo.AssertExpectations(t)
+		mockStorage.AssertExpectations(t)
+	})
+
+	t.Run("Invalid Image", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockStorage := new(MockStorage)
+		service := media.NewService(mockRepo, mockStorage, new(MockPostService), 10000, 100000, options)
+
+		mockRepo.On("ClaimPending", 10).Return([]uint{1}, nil)
+		mockRepo.On("FindByID", uint(1)).Return(&entity.Media{ID: 1, UserID: 3, Key: "3/orig.png"}, nil)
+		mockStorage.On("Open", "3/orig.png").Return(io.NopCloser(bytes.NewReader(png)), nil)
+		mockRepo.On("MarkFailed", uint(1)).Return(true, nil)
+		mockStorage.On("Delete", "3/orig.png").Return(nil)
+
+		count, err := service.ProcessPending(context.Background(), 10)
+
+		assert.NoError(t, err)
+		assert.Zero(t, count)
+		mockRepo.AssertExpectations(t)
+		mockStorage.AssertExpectations(t)
+		mockStorage.AssertNotCalled(t, "Put", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failed Elsewhere", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockStorage := new(MockStorage)
+		service := media.NewService(mockRepo, mockStorage, new(MockPostService), 10000, 100000, options)
+
+		mockRepo.On("ClaimPending", 10).Return([]uint{1}, nil)
+		mockRepo.On("FindByID", uint(1)).Return(&entity.Media{ID: 1, UserID: 3, Key: "3/orig.png"}, nil)
+		mockStorage.On("Open", "3/orig.png").Return(io.NopCloser(bytes.NewReader(png)), nil)
+		mockRepo.On("MarkFailed", uint(1)).Return(false, nil)
+
+		count, err := service.ProcessPending(context.Background(), 10)
+
+		assert.NoError(t, err)
+		assert.Zero(t, count)
+		mockStorage.AssertNotCalled(t, "Delete", mock.Anything)
+	})
+
+	t.Run("Save Fails", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockStorage := new(MockStorage)
+		service := media.NewService(mockRepo, mockStorage, new(MockPostService), 10000, 100000, options)
+
+		mockRepo.On("ClaimPending", 10).Return([]uint{1}, nil)
+		mockRepo.On("FindByID", uint(1)).Return(&entity.Media{ID: 1, UserID: 3, Key: "3/orig.jpg"}, nil)
+		mockStorage.On("Open", "3/orig.jpg").Return(io.NopCloser(bytes.NewReader(photo(t, 120, 90))), nil)
+		mockStorage.On("Put", mock.Anything, mock.Anything, "image/jpeg").Return(nil)
+		mockRepo.On("SaveProcessed", mock.Anything).Return(false, errors.New("db down"))
+		mockStorage.On("Delete", mock.Anything).Return(nil)
+		mockRepo.On("MarkFailed", uint(1)).Return(true, nil)
+
+		count, err := service.ProcessPending(context.Background(), 10)
+
+		assert.NoError(t, err)
+		assert.Zero(t, count)
+		mockStorage.AssertNumberOfCalls(t, "Delete", 4)
+		mockStorage.AssertCalled(t, "Delete", "3/orig.jpg")
+	})
+
+	t.Run("Deleted While Processing", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockStorage := new(MockStorage)
+		service := media.NewService(mockRepo, mockStorage, new(MockPostService), 10000, 100000, options)
+
+		mockRepo.On("ClaimPending", 10).Return([]uint{1}, nil)
+		mockRepo.On("FindByID", uint(1)).Return(&entity.Media{ID: 1, UserID: 3, Key: "3/orig.jpg"}, nil)
+		mockStorage.On("Open", "3/orig.jpg").Return(io.NopCloser(bytes.NewReader(photo(t, 120, 90))), nil)
+		mockStorage.On("Put", mock.Anything, mock.Anything, "image/jpeg").Return(nil)
+		mockRepo.On("SaveProcessed", mock.Anything).Return(false, nil)
+		mockStorage.On("Delete", mock.MatchedBy(func(key string) bool { return key != "3/orig.jpg" })).Return(nil)
+
+		count, err := service.ProcessPending(context.Background(), 10)
+
+		assert.NoError(t, err)
+		assert.Zero(t, count)
+		mockStorage.AssertNumberOfCalls(t, "Delete", 3)
+		mockRepo.AssertNotCalled(t, "MarkFailed", mock.Anything)
+	})
+}
@@ -1,34 +1,81 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"time"
 
+	"post/internal/auth"
+	"post/internal/media"
+	"post/internal/pkg/cache"
 	"post/internal/pkg/config"
 	"post/internal/pkg/database"
+	"post/internal/pkg/imageproc"
 	"post/internal/pkg/logger"
+	"post/internal/pkg/storage"
+	"post/internal/post"
 	"post/internal/router"
 
 	"github.com/gin-gonic/gin"
 )
 
 type Server struct {
-	router *gin.Engine
+	router    *gin.Engine
+	scheduler *post.Scheduler
+	processor *media.Processor
+	cleaner   *auth.Cleaner
 }
 
-func NewServer() *Server {
+// NewServer wires the API. When publishScheduled is false the server still
+// watches for scheduled posts going live, but leaves publishing them to a
+// separate `post scheduler` process. When processMedia is false uploaded
+// post images wait for another API process to pick them up.
+func NewServer(publishScheduled, processMedia bool) *Server {
 	cfg := config.LoadConfig()
 	logger.InitLogger(cfg.App.Env)
 	database.Connect(cfg)
 
-	r := router.Init(cfg)
+	// Initialize Cache (100 items), shared by the router and the scheduler
+	postCache, err := cache.NewLRUCache(100)
+	if err != nil {
+		log.Fatalf("Failed to create cache: %v", err)
+	}
+
+	r := router.Init(cfg, postCache)
 
-	return &Server{
-		router: r,
+	postService := post.NewService(post.NewRepository(database.GetDB()), postCache)
+	interval := time.Duration(cfg.Scheduler.Interval) * time.Second
+
+	srv := &Server{
+		router:    r,
+		scheduler: post.NewScheduler(postService, interval, publishScheduled),
+		cleaner:   auth.NewCleaner(auth.NewRepository(database.GetDB()), time.Duration(cfg.JWT.CleanupInterval)*time.Minute),
 	}
+
+	if processMedia {
+		mediaStorage, err := storage.New(cfg.Media, cfg.App.BaseURL)
+		if err != nil {
+			log.Fatalf("Failed to set up media storage: %v", err)
+		}
+		images := cfg.Media.Images
+		mediaService := media.NewService(media.NewRepository(database.GetDB()), mediaStorage, postService, cfg.Media.MaxSize, cfg.Media.Quota, imageproc.Options{
+			MaxWidth: images.MaxWidth,
+			Widths:   images.ThumbnailWidths,
+			Quality:  images.Quality,
+		})
+		srv.processor = media.NewProcessor(mediaService, time.Duration(images.Interval)*time.Second)
+	}
+	return srv
 }
 
 func (s *Server) Run(port int) {
+	go s.scheduler.Run(context.Background())
+	go s.cleaner.Run(context.Background())
+	if s.processor != nil {
+		go s.processor.Run(context.Background())
+	}
+
 	addr := fmt.Sprintf(":%d", port)
 	log.Printf("Server starting on port %d", port)
 	if err := s.router.Run(addr); err != nil {
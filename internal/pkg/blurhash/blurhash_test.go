@@ -0,0 +1,76 @@
+package blurhash_test
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"post/internal/pkg/blurhash"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const characters = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz#$%*+,-.:;=?@[]^_{|}~"
+
+func decode83(s string) int {
+	value := 0
+	for _, c := range s {
+		value = value*83 + strings.IndexRune(characters, c)
+	}
+	return value
+}
+
+func solid(c color.Color, width, height int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		for x := range width {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestEncode(t *testing.T) {
+	t.Run("Length", func(t *testing.T) {
+		for _, size := range [][2]int{{1, 1}, {4, 3}, {9, 9}} {
+			hash, err := blurhash.Encode(solid(color.White, 8, 8), size[0], size[1])
+
+			assert.NoError(t, err)
+			assert.Len(t, hash, 4+2*size[0]*size[1])
+			assert.Equal(t, (size[0]-1)+(size[1]-1)*9, decode83(hash[:1]))
+		}
+	})
+
+	t.Run("SolidColor", func(t *testing.T) {
+		hash, err := blurhash.Encode(solid(color.RGBA{R: 200, G: 100, B: 50, A: 255}, 16, 8), 4, 3)
+
+		assert.NoError(t, err)
+		// The average color survives the round trip through linear RGB
+		assert.Equal(t, 200<<16|100<<8|50, decode83(hash[2:6]))
+	})
+
+	t.Run("Gradient", func(t *testing.T) {
+		img := image.NewGray(image.Rect(0, 0, 16, 16))
+		for y := range 16 {
+			for x := range 16 {
+				img.SetGray(x, y, color.Gray{Y: uint8(x * 16)})
+			}
+		}
+
+		hash, err := blurhash.Encode(img, 4, 3)
+		flat, _ := blurhash.Encode(solid(color.Gray{Y: 120}, 16, 16), 4, 3)
+
+		assert.NoError(t, err)
+		// hash[1] is the largest AC component, which carries the detail
+		assert.Greater(t, decode83(hash[1:2]), decode83(flat[1:2]))
+	})
+
+	t.Run("InvalidComponents", func(t *testing.T) {
+		for _, size := range [][2]int{{0, 1}, {1, 0}, {10, 1}, {1, 10}} {
+			_, err := blurhash.Encode(solid(color.White, 4, 4), size[0], size[1])
+
+			assert.ErrorIs(t, err, blurhash.ErrInvalidComponents)
+		}
+	})
+}
@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"strings"
 	"time"
 
 	lru "github.com/hashicorp/golang-lru/v2"
@@ -10,6 +11,7 @@ type Cache interface {
 	Get(key string) (any, bool)
 	Set(key string, value any)
 	Delete(key string)
+	DeletePrefix(prefix string)
 	Purge()
 }
 
@@ -38,6 +40,15 @@ func (c *lruCache) Delete(key string) {
 	c.cache.Remove(key)
 }
 
+// DeletePrefix removes every key starting with prefix, e.g. all pages of a list.
+func (c *lruCache) DeletePrefix(prefix string) {
+	for _, key := range c.cache.Keys() {
+		if strings.HasPrefix(key, prefix) {
+			c.cache.Remove(key)
+		}
+	}
+}
+
 func (c *lruCache) Purge() {
 	c.cache.Purge()
 }
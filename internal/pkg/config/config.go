@@ -4,20 +4,28 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	App      AppConfig
-	Database DatabaseConfig
-	JWT      JWTConfig
+	App       AppConfig
+	Database  DatabaseConfig
+	JWT       JWTConfig
+	Scheduler SchedulerConfig
+	Comment   CommentConfig
+	Media     MediaConfig
 }
 
 type AppConfig struct {
-	Name          string `mapstructure:"APP_NAME"`
-	Port          string `mapstructure:"APP_PORT"`
-	Env           string `mapstructure:"APP_ENV"`
+	Name string `mapstructure:"APP_NAME"`
+	Port string `mapstructure:"APP_PORT"`
+	Env  string `mapstructure:"APP_ENV"`
+	// BaseURL is the public address of the API, used for absolute links in
+	// feeds and sitemaps. It has no trailing slash.
+	BaseURL       string `mapstructure:"APP_BASE_URL"`
 	AdminUser     string `mapstructure:"ADMIN_USER"`
 	AdminPassword string `mapstructure:"ADMIN_PASSWORD"`
 }
@@ -32,9 +40,77 @@ type DatabaseConfig struct {
 }
 
 type JWTConfig struct {
-	Secret        string
-	Expiry        int
-	RefreshExpiry int
+	// Algorithm is HS256, signing with Secret, or RS256 or EdDSA, signing
+	// with Keys.
+	Algorithm string
+	Secret    string
+	// Keys are the asymmetric signing keys. The one with the latest
+	// NotBefore that has passed signs new tokens.
+	Keys []KeyConfig
+	// KeyGracePeriod is how long a replaced key still verifies tokens, in
+	// hours.
+	KeyGracePeriod int
+	Expiry         int
+	RefreshExpiry  int
+	// SilentRefresh keeps the deprecated refresh through the X-Refresh-Token
+	// header, which answers with X-New-Token and never rotates the refresh
+	// token.
+	SilentRefresh bool
+	// CleanupInterval is how often expired revocations and refresh tokens
+	// are deleted, in minutes.
+	CleanupInterval int
+}
+
+// KeyConfig is one signing key, read from a PEM file holding its private key.
+type KeyConfig struct {
+	ID        string
+	Path      string
+	NotBefore time.Time
+}
+
+type SchedulerConfig struct {
+	// Interval is how often scheduled posts are checked, in seconds.
+	Interval int
+}
+
+type CommentConfig struct {
+	// MaxDepth is how deeply replies can nest. Top-level comments have depth 0.
+	MaxDepth int
+}
+
+type MediaConfig struct {
+	// Driver is where uploads are stored: "local" or "s3".
+	Driver string
+	// Dir is the upload directory of the local driver.
+	Dir string
+	// MaxSize is the largest accepted upload and Quota the total size each
+	// user may store, both in bytes.
+	MaxSize int64
+	Quota   int64
+	S3      S3Config
+	Images  ImageConfig
+}
+
+// ImageConfig controls how post images are processed in the background.
+type ImageConfig struct {
+	// MaxWidth is the widest a processed image is kept, in pixels.
+	MaxWidth int
+	// ThumbnailWidths are the widths thumbnails are rendered at.
+	ThumbnailWidths []int
+	// Quality is the JPEG quality, 1 to 100.
+	Quality int
+	// Interval is how often pending images are picked up, in seconds.
+	Interval int
+}
+
+type S3Config struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	UseSSL    bool
+	PublicURL string
 }
 
 func LoadConfig() *Config {
@@ -49,11 +125,40 @@ func LoadConfig() *Config {
 	refreshExpiryStr := getEnv("JWT_REFRESH_EXPIRY", "168")
 	refreshExpiry, _ := strconv.Atoi(refreshExpiryStr)
 
+	silentRefresh, err := strconv.ParseBool(getEnv("JWT_SILENT_REFRESH", "true"))
+	if err != nil {
+		silentRefresh = true
+	}
+
+	schedulerIntervalStr := getEnv("SCHEDULER_INTERVAL", "30")
+	schedulerInterval, err := strconv.Atoi(schedulerIntervalStr)
+	if err != nil || schedulerInterval <= 0 {
+		schedulerInterval = 30
+	}
+
+	commentMaxDepthStr := getEnv("COMMENT_MAX_DEPTH", "5")
+	commentMaxDepth, err := strconv.Atoi(commentMaxDepthStr)
+	if err != nil || commentMaxDepth < 0 {
+		commentMaxDepth = 5
+	}
+
+	mediaMaxSize := getEnvInt64("MEDIA_MAX_SIZE", 10<<20)
+	mediaQuota := getEnvInt64("MEDIA_QUOTA", 100<<20)
+	imageQuality := getEnvInt64("MEDIA_IMAGE_QUALITY", 82)
+	if imageQuality > 100 {
+		imageQuality = 82
+	}
+	s3UseSSL, err := strconv.ParseBool(getEnv("S3_USE_SSL", "true"))
+	if err != nil {
+		s3UseSSL = true
+	}
+
 	return &Config{
 		App: AppConfig{
 			Name:          getEnv("APP_NAME", "post-api"),
 			Port:          getEnv("APP_PORT", "8080"),
 			Env:           getEnv("APP_ENV", "dev"),
+			BaseURL:       strings.TrimRight(getEnv("APP_BASE_URL", "http://localhost:8080"), "/"),
 			AdminUser:     getEnv("ADMIN_USER", "admin"),
 			AdminPassword: getEnv("ADMIN_PASSWORD", "secret"),
 		},
@@ -66,9 +171,41 @@ func LoadConfig() *Config {
 			SSLMode:  getEnv("DB_SSLMODE", "disable"),
 		},
 		JWT: JWTConfig{
-			Secret:        getEnv("JWT_SECRET", "supersecretkey"),
-			Expiry:        expiry,
-			RefreshExpiry: refreshExpiry,
+			Algorithm:       getEnv("JWT_ALGORITHM", "HS256"),
+			Secret:          getEnv("JWT_SECRET", "supersecretkey"),
+			Keys:            getEnvKeys("JWT_KEYS"),
+			KeyGracePeriod:  int(getEnvInt64("JWT_KEY_GRACE_PERIOD", int64(refreshExpiry))),
+			Expiry:          expiry,
+			RefreshExpiry:   refreshExpiry,
+			SilentRefresh:   silentRefresh,
+			CleanupInterval: int(getEnvInt64("JWT_CLEANUP_INTERVAL", 60)),
+		},
+		Scheduler: SchedulerConfig{
+			Interval: schedulerInterval,
+		},
+		Comment: CommentConfig{
+			MaxDepth: commentMaxDepth,
+		},
+		Media: MediaConfig{
+			Driver:  getEnv("MEDIA_DRIVER", "local"),
+			Dir:     getEnv("MEDIA_DIR", "uploads"),
+			MaxSize: mediaMaxSize,
+			Quota:   mediaQuota,
+			S3: S3Config{
+				Endpoint:  getEnv("S3_ENDPOINT", "localhost:9000"),
+				Region:    getEnv("S3_REGION", "us-east-1"),
+				Bucket:    getEnv("S3_BUCKET", "media"),
+				AccessKey: getEnv("S3_ACCESS_KEY", ""),
+				SecretKey: getEnv("S3_SECRET_KEY", ""),
+				UseSSL:    s3UseSSL,
+				PublicURL: getEnv("S3_PUBLIC_URL", ""),
+			},
+			Images: ImageConfig{
+				MaxWidth:        int(getEnvInt64("MEDIA_IMAGE_MAX_WIDTH", 2048)),
+				ThumbnailWidths: getEnvInts("MEDIA_THUMBNAIL_WIDTHS", []int{320, 640, 1280}),
+				Quality:         int(imageQuality),
+				Interval:        int(getEnvInt64("MEDIA_PROCESS_INTERVAL", 5)),
+			},
 		},
 	}
 }
@@ -79,3 +216,57 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+// getEnvInt64 parses a positive integer, falling back on missing or bad values.
+func getEnvInt64(key string, fallback int64) int64 {
+	n, err := strconv.ParseInt(getEnv(key, ""), 10, 64)
+	if err != nil || n <= 0 {
+		return fallback
+	}
+	return n
+}
+
+// getEnvInts parses a comma separated list of positive integers, falling
+// back when the variable is missing or any entry is bad.
+func getEnvInts(key string, fallback []int) []int {
+	value := getEnv(key, "")
+	if value == "" {
+		return fallback
+	}
+	var ints []int
+	for _, field := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || n <= 0 {
+			return fallback
+		}
+		ints = append(ints, n)
+	}
+	return ints
+}
+
+// getEnvKeys parses a comma separated list of signing keys, each written as
+// kid=path or kid=path@not_before with an RFC 3339 time. A bad entry is fatal
+// rather than skipped, so a typo never drops a key.
+func getEnvKeys(key string) []KeyConfig {
+	value := getEnv(key, "")
+	if value == "" {
+		return nil
+	}
+	var keys []KeyConfig
+	for _, entry := range strings.Split(value, ",") {
+		id, path, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || id == "" || path == "" {
+			log.Fatalf("Invalid %s entry %q, expected kid=path[@not_before]", key, entry)
+		}
+		k := KeyConfig{ID: id, Path: path}
+		if path, notBefore, ok := strings.Cut(path, "@"); ok {
+			t, err := time.Parse(time.RFC3339, notBefore)
+			if err != nil {
+				log.Fatalf("Invalid %s entry %q: %v", key, entry, err)
+			}
+			k.Path, k.NotBefore = path, t
+		}
+		keys = append(keys, k)
+	}
+	return keys
+}
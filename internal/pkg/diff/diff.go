@@ -0,0 +1,264 @@
+package diff
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Diffing takes time proportional to the size of the inputs times the
+// number of changes, so inputs beyond these limits are refused.
+const (
+	MaxLines = 5000
+	MaxBytes = 512 << 10
+)
+
+var ErrTooLarge = errors.New("input too large to diff")
+
+type op int
+
+const (
+	opEqual op = iota
+	opDelete
+	opInsert
+)
+
+type edit struct {
+	op   op
+	text string
+}
+
+// Unified returns a line-based unified diff turning a into b, labelled with
+// fromName and toName and with the given number of context lines around each
+// change. It returns an empty string when a and b are equal, and
+// ErrTooLarge when either one has more than MaxLines lines or MaxBytes bytes.
+func Unified(fromName, toName, a, b string, context int) (string, error) {
+	if len(a) > MaxBytes || len(b) > MaxBytes {
+		return "", ErrTooLarge
+	}
+	aLines, bLines := splitLines(a), splitLines(b)
+	if len(aLines) > MaxLines || len(bLines) > MaxLines {
+		return "", ErrTooLarge
+	}
+	edits := myers(aLines, bLines)
+
+	// aBefore[i] and bBefore[i] count the lines of a and b preceding edit i
+	aBefore := make([]int, len(edits)+1)
+	bBefore := make([]int, len(edits)+1)
+	changed := false
+	for i, e := range edits {
+		aBefore[i+1], bBefore[i+1] = aBefore[i], bBefore[i]
+		if e.op != opInsert {
+			aBefore[i+1]++
+		}
+		if e.op != opDelete {
+			bBefore[i+1]++
+		}
+		if e.op != opEqual {
+			changed = true
+		}
+	}
+	if !changed {
+		return "", nil
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromName, toName)
+
+	for i := 0; i < len(edits); {
+		if edits[i].op == opEqual {
+			i++
+			continue
+		}
+
+		start := max(0, i-context)
+		end := i
+		for end < len(edits) {
+			if edits[end].op != opEqual {
+				end++
+				continue
+			}
+			run := end
+			for run < len(edits) && edits[run].op == opEqual {
+				run++
+			}
+			// Close the hunk unless the next change is close enough to share context
+			if run == len(edits) || run-end > 2*context {
+				end = min(end+context, len(edits))
+				break
+			}
+			end = run
+		}
+
+		aLen := aBefore[end] - aBefore[start]
+		bLen := bBefore[end] - bBefore[start]
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(aBefore[start], aLen), hunkRange(bBefore[start], bLen))
+		for _, e := range edits[start:end] {
+			switch e.op {
+			case opEqual:
+				out.WriteString(" ")
+			case opDelete:
+				out.WriteString("-")
+			case opInsert:
+				out.WriteString("+")
+			}
+			out.WriteString(e.text)
+			out.WriteString("\n")
+		}
+		i = end
+	}
+
+	return out.String(), nil
+}
+
+func hunkRange(before, length int) string {
+	// An empty range points at the line before it, as in GNU diff
+	if length == 0 {
+		return fmt.Sprintf("%d,0", before)
+	}
+	return fmt.Sprintf("%d,%d", before+1, length)
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// myers computes a shortest edit script from a to b with the linear space
+// variant of Myers' O(ND) algorithm, so memory stays O(N+M) however far
+// apart the inputs are.
+func myers(a, b []string) []edit {
+	var s script
+	s.compare(a, b)
+	return s
+}
+
+type script []edit
+
+func (s *script) add(o op, lines []string) {
+	for _, line := range lines {
+		*s = append(*s, edit{o, line})
+	}
+}
+
+// compare appends the edits turning a into b. Common ends are matched
+// directly; what is left is split where the forward and backward searches
+// meet and each half is compared on its own.
+func (s *script) compare(a, b []string) {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	s.add(opEqual, a[:prefix])
+	a, b = a[prefix:], b[prefix:]
+
+	suffix := 0
+	for suffix < len(a) && suffix < len(b) && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	common := a[len(a)-suffix:]
+	a, b = a[:len(a)-suffix], b[:len(b)-suffix]
+
+	switch {
+	case len(a) == 0:
+		s.add(opInsert, b)
+	case len(b) == 0:
+		s.add(opDelete, a)
+	default:
+		if x, y, ok := middle(a, b); ok {
+			s.compare(a[:x], b[:y])
+			s.compare(a[x:], b[y:])
+		} else {
+			s.add(opDelete, a)
+			s.add(opInsert, b)
+		}
+	}
+	s.add(opEqual, common)
+}
+
+// middle runs the search from both ends at once and returns the point where
+// the two paths first overlap, which lies on a shortest edit script. It
+// reports false when a and b have nothing in common.
+func middle(a, b []string) (int, int, bool) {
+	n, m := len(a), len(b)
+	maxD := (n + m + 1) / 2
+	offset := maxD + 1
+	// vf[offset+k] is the furthest x reached on diagonal k from the start, vb
+	// the same counted from the end
+	vf := make([]int, 2*offset+1)
+	vb := make([]int, 2*offset+1)
+	for i := range vf {
+		vf[i], vb[i] = -1, -1
+	}
+	vf[offset+1], vb[offset+1] = 0, 0
+
+	delta := n - m
+	// With an odd delta the forward path meets the backward one, otherwise the
+	// backward path meets the forward one
+	front := delta%2 != 0
+	// Diagonals that ran off an edge of the grid are not searched again
+	var fStart, fEnd, bStart, bEnd int
+
+	for d := 0; d < maxD; d++ {
+		for k := -d + fStart; k <= d-fEnd; k += 2 {
+			var x int
+			if k == -d || (k != d && vf[offset+k-1] < vf[offset+k+1]) {
+				x = vf[offset+k+1]
+			} else {
+				x = vf[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			vf[offset+k] = x
+
+			switch {
+			case x > n:
+				fEnd += 2
+			case y > m:
+				fStart += 2
+			case front:
+				i := offset + delta - k
+				if i >= 0 && i < len(vb) && vb[i] != -1 && x >= n-vb[i] {
+					return x, y, true
+				}
+			}
+		}
+
+		for k := -d + bStart; k <= d-bEnd; k += 2 {
+			var x int
+			if k == -d || (k != d && vb[offset+k-1] < vb[offset+k+1]) {
+				x = vb[offset+k+1]
+			} else {
+				x = vb[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[n-x-1] == b[m-y-1] {
+				x++
+				y++
+			}
+			vb[offset+k] = x
+
+			switch {
+			case x > n:
+				bEnd += 2
+			case y > m:
+				bStart += 2
+			case !front:
+				i := offset + delta - k
+				if i >= 0 && i < len(vf) && vf[i] != -1 {
+					fx := vf[i]
+					fy := fx - (delta - k)
+					if fx >= n-x {
+						return fx, fy, true
+					}
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,96 @@
+package diff_test
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"post/internal/pkg/diff"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{
+			name: "Equal",
+			a:    "one\ntwo\n",
+			b:    "one\ntwo\n",
+			want: "",
+		},
+		{
+			name: "FromEmpty",
+			a:    "",
+			b:    "one\ntwo\n",
+			want: "--- a\n+++ b\n@@ -0,0 +1,2 @@\n+one\n+two\n",
+		},
+		{
+			name: "ToEmpty",
+			a:    "one\ntwo\n",
+			b:    "",
+			want: "--- a\n+++ b\n@@ -1,2 +0,0 @@\n-one\n-two\n",
+		},
+		{
+			name: "ChangedLine",
+			a:    "one\ntwo\nthree\n",
+			b:    "one\n2\nthree\n",
+			want: "--- a\n+++ b\n@@ -1,3 +1,3 @@\n one\n-two\n+2\n three\n",
+		},
+		{
+			name: "SeparateHunks",
+			a:    "1\n2\n3\n4\n5\n6\n7\n8\n",
+			b:    "x\n2\n3\n4\n5\n6\n7\ny\n",
+			want: "--- a\n+++ b\n@@ -1,2 +1,2 @@\n-1\n+x\n 2\n@@ -7,2 +7,2 @@\n 7\n-8\n+y\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := diff.Unified("a", "b", tc.a, tc.b, 1)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestUnifiedLargeInput(t *testing.T) {
+	lines := func(prefix string, n int) string {
+		var sb strings.Builder
+		for i := range n {
+			fmt.Fprintf(&sb, "%s %d\n", prefix, i)
+		}
+		return sb.String()
+	}
+
+	t.Run("NothingInCommon", func(t *testing.T) {
+		a, b := lines("old", 4000), lines("new", 4000)
+
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		got, err := diff.Unified("a", "b", a, b, 3)
+		runtime.ReadMemStats(&after)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 8000+3, strings.Count(got, "\n"))
+		// The edit script and output dominate; the search itself stays linear
+		assert.Less(t, after.TotalAlloc-before.TotalAlloc, uint64(16<<20))
+	})
+
+	t.Run("TooManyLines", func(t *testing.T) {
+		_, err := diff.Unified("a", "b", lines("old", diff.MaxLines+1), "", 3)
+
+		assert.ErrorIs(t, err, diff.ErrTooLarge)
+	})
+
+	t.Run("TooManyBytes", func(t *testing.T) {
+		_, err := diff.Unified("a", "b", "", strings.Repeat("x", diff.MaxBytes+1), 3)
+
+		assert.ErrorIs(t, err, diff.ErrTooLarge)
+	})
+}
@@ -0,0 +1,155 @@
+// Package imageproc prepares uploaded images for serving: it applies the
+// EXIF orientation, caps their size, renders thumbnails and computes a
+// BlurHash placeholder.
+package imageproc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	"post/internal/pkg/blurhash"
+
+	"github.com/disintegration/imaging"
+	_ "golang.org/x/image/webp"
+)
+
+var (
+	ErrInvalidImage  = errors.New("file is not a valid image")
+	ErrTooManyPixels = errors.New("image has too many pixels")
+)
+
+const (
+	// hashSize is the width and height the image is shrunk to before its
+	// BlurHash is computed. The placeholder is blurry anyway.
+	hashSize        = 32
+	hashXComponents = 4
+	hashYComponents = 3
+)
+
+type Options struct {
+	// MaxWidth is the widest the processed image may be. Wider images are
+	// scaled down.
+	MaxWidth int
+	// Widths are the thumbnail widths to render. Widths not smaller than
+	// the processed image are skipped.
+	Widths []int
+	// Quality is the JPEG quality, 1 to 100.
+	Quality int
+	// MaxPixels guards against decompression bombs: larger images are
+	// rejected before they are decoded.
+	MaxPixels int
+}
+
+// Image is an encoded image.
+type Image struct {
+	Data        []byte
+	ContentType string
+	Ext         string
+	Width       int
+	Height      int
+}
+
+// Variant is a thumbnail. Name is its width followed by "w", like "320w".
+type Variant struct {
+	Name string
+	Image
+}
+
+type Result struct {
+	Image
+	Variants []Variant
+	Blurhash string
+}
+
+// Process decodes the image in r and re-encodes it along with its
+// thumbnails. Re-encoding drops all metadata, EXIF and GPS included. Images
+// with transparency become PNGs, all others JPEGs: x/image and imaging
+// decode WebP but cannot encode it. Only the first frame of an animated GIF
+// is kept.
+func Process(r io.Reader, opts Options) (*Result, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	if opts.MaxPixels > 0 && config.Width*config.Height > opts.MaxPixels {
+		return nil, fmt.Errorf("%w: %dx%d", ErrTooManyPixels, config.Width, config.Height)
+	}
+
+	img, err := imaging.Decode(bytes.NewReader(data), imaging.AutoOrientation(true))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	if opts.MaxWidth > 0 && img.Bounds().Dx() > opts.MaxWidth {
+		img = imaging.Resize(img, opts.MaxWidth, 0, imaging.Lanczos)
+	}
+	opaque := isOpaque(img)
+
+	main, err := encode(img, opaque, opts.Quality)
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{Image: *main}
+
+	for _, width := range opts.Widths {
+		if width <= 0 || width >= img.Bounds().Dx() {
+			continue
+		}
+		thumb, err := encode(imaging.Resize(img, width, 0, imaging.Lanczos), opaque, opts.Quality)
+		if err != nil {
+			return nil, err
+		}
+		result.Variants = append(result.Variants, Variant{Name: fmt.Sprintf("%dw", width), Image: *thumb})
+	}
+
+	small := imaging.Fit(img, hashSize, hashSize, imaging.Box)
+	result.Blurhash, err = blurhash.Encode(small, hashXComponents, hashYComponents)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func encode(img image.Image, opaque bool, quality int) (*Image, error) {
+	var buf bytes.Buffer
+	out := &Image{Width: img.Bounds().Dx(), Height: img.Bounds().Dy()}
+	if opaque {
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+			return nil, err
+		}
+		out.ContentType, out.Ext = "image/jpeg", ".jpg"
+	} else {
+		encoder := png.Encoder{CompressionLevel: png.BestCompression}
+		if err := encoder.Encode(&buf, img); err != nil {
+			return nil, err
+		}
+		out.ContentType, out.Ext = "image/png", ".png"
+	}
+	out.Data = buf.Bytes()
+	return out, nil
+}
+
+// isOpaque reports whether every pixel of img is fully opaque.
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a != 0xffff {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,123 @@
+package imageproc_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+
+	"post/internal/pkg/imageproc"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func pngOf(img image.Image) []byte {
+	var buf bytes.Buffer
+	_ = png.Encode(&buf, img)
+	return buf.Bytes()
+}
+
+func filled(width, height int, c color.Color) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		for x := range width {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+// withEXIF returns a JPEG carrying an EXIF orientation tag and a marker
+// string that must not survive processing.
+func withEXIF(img image.Image, orientation uint16) []byte {
+	var buf bytes.Buffer
+	_ = jpeg.Encode(&buf, img, nil)
+	data := buf.Bytes()
+
+	tiff := []byte("II*\x00")
+	tiff = binary.LittleEndian.AppendUint32(tiff, 8)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 1)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 0x0112)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 3)
+	tiff = binary.LittleEndian.AppendUint32(tiff, 1)
+	tiff = binary.LittleEndian.AppendUint16(tiff, orientation)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 0)
+	tiff = binary.LittleEndian.AppendUint32(tiff, 0)
+	tiff = append(tiff, "SECRET-GPS"...)
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xFF, 0xE1}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	// The APP1 segment goes right after the SOI marker
+	return append(append(append([]byte{}, data[:2]...), segment...), data[2:]...)
+}
+
+func TestProcess(t *testing.T) {
+	opts := imageproc.Options{MaxWidth: 200, Widths: []int{50, 100, 400}, Quality: 80, MaxPixels: 1000 * 1000}
+
+	t.Run("OpaqueBecomesJPEG", func(t *testing.T) {
+		result, err := imageproc.Process(bytes.NewReader(pngOf(filled(150, 100, color.White))), opts)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "image/jpeg", result.ContentType)
+		assert.Equal(t, 150, result.Width)
+		assert.Equal(t, 100, result.Height)
+		assert.NotEmpty(t, result.Blurhash)
+	})
+
+	t.Run("TransparentStaysPNG", func(t *testing.T) {
+		result, err := imageproc.Process(bytes.NewReader(pngOf(filled(60, 60, color.NRGBA{R: 255, A: 128}))), opts)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "image/png", result.ContentType)
+		assert.Equal(t, ".png", result.Ext)
+	})
+
+	t.Run("ScalesAndThumbnails", func(t *testing.T) {
+		result, err := imageproc.Process(bytes.NewReader(pngOf(filled(800, 400, color.White))), opts)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 200, result.Width)
+		assert.Equal(t, 100, result.Height)
+		// 400w is not smaller than the scaled image, so it is skipped
+		assert.Len(t, result.Variants, 2)
+		assert.Equal(t, "50w", result.Variants[0].Name)
+		assert.Equal(t, 25, result.Variants[0].Height)
+		assert.Equal(t, "100w", result.Variants[1].Name)
+	})
+
+	t.Run("AppliesOrientationAndStripsEXIF", func(t *testing.T) {
+		// Orientation 6 means the camera was turned, so the image is shown rotated
+		data := withEXIF(filled(120, 60, color.White), 6)
+		assert.Contains(t, string(data), "SECRET-GPS")
+
+		result, err := imageproc.Process(bytes.NewReader(data), opts)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 60, result.Width)
+		assert.Equal(t, 120, result.Height)
+		assert.NotContains(t, string(result.Data), "Exif")
+		assert.NotContains(t, string(result.Data), "SECRET-GPS")
+	})
+
+	t.Run("TooManyPixels", func(t *testing.T) {
+		limited := opts
+		limited.MaxPixels = 100
+
+		_, err := imageproc.Process(bytes.NewReader(pngOf(filled(20, 20, color.White))), limited)
+
+		assert.ErrorIs(t, err, imageproc.ErrTooManyPixels)
+	})
+
+	t.Run("NotAnImage", func(t *testing.T) {
+		_, err := imageproc.Process(strings.NewReader("<svg onload=alert(1)>"), opts)
+
+		assert.ErrorIs(t, err, imageproc.ErrInvalidImage)
+	})
+}
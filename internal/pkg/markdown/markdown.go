@@ -0,0 +1,26 @@
+package markdown
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+// renderer is safe for concurrent use.
+var renderer = goldmark.New(
+	goldmark.WithExtensions(extension.Strikethrough),
+	goldmark.WithRendererOptions(html.WithUnsafe()),
+)
+
+// ToHTML renders CommonMark with GitHub's strikethrough to HTML. Raw HTML and
+// every link destination are passed through, so the output must be sanitized
+// before it is shown to anyone.
+func ToHTML(src string) string {
+	var b bytes.Buffer
+	if err := renderer.Convert([]byte(src), &b); err != nil {
+		return ""
+	}
+	return b.String()
+}
@@ -0,0 +1,117 @@
+package markdown_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"post/internal/pkg/markdown"
+	"post/internal/pkg/sanitize"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"Heading", "# Title\n\npara", "<h1>Title</h1>\n<p>para</p>\n"},
+		{"Setext", "Title\n===", "<h1>Title</h1>\n"},
+		{"List", "- a\n- b", "<ul>\n<li>a</li>\n<li>b</li>\n</ul>\n"},
+		{"OrderedList", "3. a\n4. b", "<ol start=\"3\">\n<li>a</li>\n<li>b</li>\n</ol>\n"},
+		{"Quote", "> quote", "<blockquote>\n<p>quote</p>\n</blockquote>\n"},
+		{"NotAList", "Born in\n1984. A good year", "<p>Born in\n1984. A good year</p>\n"},
+
+		// Emphasis
+		{"StrongEmphasis", "***both***", "<p><em><strong>both</strong></em></p>\n"},
+		{"NestedEmphasis", "*a **b** c*", "<p><em>a <strong>b</strong> c</em></p>\n"},
+		{"NestedStrong", "**a *b* c**", "<p><strong>a <em>b</em> c</strong></p>\n"},
+		{"RuleOfThree", "*foo**bar*", "<p><em>foo**bar</em></p>\n"},
+		{"IntrawordUnderscore", "snake_case_name", "<p>snake_case_name</p>\n"},
+		{"Strikethrough", "~~gone~~", "<p><del>gone</del></p>\n"},
+		{"Escaped", `\*not em\*`, "<p>*not em*</p>\n"},
+
+		// Code
+		{"CodeSpan", "`<script>`", "<p><code>&lt;script&gt;</code></p>\n"},
+		{"LinkInCode", "`[x](https://x.y)`", "<p><code>[x](https://x.y)</code></p>\n"},
+		{"CodeInLink", "[`code`](https://x.y)", "<p><a href=\"https://x.y\"><code>code</code></a></p>\n"},
+		{"FencedCode", "```go\n<b>\n```", "<pre><code class=\"language-go\">&lt;b&gt;\n</code></pre>\n"},
+
+		// Links and attribute quoting
+		{"Link", "[a *b*](https://x.y)", "<p><a href=\"https://x.y\">a <em>b</em></a></p>\n"},
+		{"ParensInURL", "[x](https://x.y/a_(b))", "<p><a href=\"https://x.y/a_(b)\">x</a></p>\n"},
+		{"EscapedQuoteInTitle", `[x](https://x.y "a \"b\" c")`, "<p><a href=\"https://x.y\" title=\"a &quot;b&quot; c\">x</a></p>\n"},
+		{"QuoteInURL", `[x](https://x.y/"onmouseover=")`, "<p><a href=\"https://x.y/%22onmouseover=%22\">x</a></p>\n"},
+		{"ImageAlt", `![a "b"](/i.png)`, "<p><img src=\"/i.png\" alt=\"a &quot;b&quot;\"></p>\n"},
+		{"Autolink", "<https://x.y?a=1&b=2>", "<p><a href=\"https://x.y?a=1&amp;b=2\">https://x.y?a=1&amp;b=2</a></p>\n"},
+		{"EmailAutolink", "<me@example.com>", "<p><a href=\"mailto:me@example.com\">me@example.com</a></p>\n"},
+		{"Entities", "x &amp; y & z", "<p>x &amp; y &amp; z</p>\n"},
+		{"ReferenceLink", "[x][ref]\n\n[ref]: https://x.y", "<p><a href=\"https://x.y\">x</a></p>\n"},
+		{"BareURL", "see https://x.y", "<p>see https://x.y</p>\n"},
+
+		// Lists and breaks
+		{"LooseList", "- a\n\n- b", "<ul>\n<li>\n<p>a</p>\n</li>\n<li>\n<p>b</p>\n</li>\n</ul>\n"},
+		{"NestedList", "- a\n  - b", "<ul>\n<li>a\n<ul>\n<li>b</li>\n</ul>\n</li>\n</ul>\n"},
+		{"HardBreak", "a  \nb", "<p>a<br>\nb</p>\n"},
+		{"Rule", "***", "<hr>\n"},
+		{"IndentedCode", "    x < y", "<pre><code>x &lt; y\n</code></pre>\n"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, markdown.ToHTML(tc.in))
+		})
+	}
+}
+
+// TestToHTMLSanitized covers what reaches readers: markdown passes raw HTML
+// and any URL through, and sanitize.HTML has to catch it.
+func TestToHTMLSanitized(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"JavascriptLink", "[x](javascript:alert(1))", "<p><a rel=\"nofollow\">x</a></p>\n"},
+		{"UpperCaseScheme", "[x](JAVASCRIPT:alert(1))", "<p><a rel=\"nofollow\">x</a></p>\n"},
+		{"EntityInScheme", "[x](java&#x09;script:alert(1))", "<p><a rel=\"nofollow\">x</a></p>\n"},
+		{"BracketedDestination", "[x](<javascript:alert(1)>)", "<p><a rel=\"nofollow\">x</a></p>\n"},
+		{"JavascriptAutolink", "<javascript:alert(1)>", "<p><a rel=\"nofollow\">javascript:alert(1)</a></p>\n"},
+		{"DataImage", "![x](data:text/html,<script>alert(1)</script>)", "<p><img alt=\"x\"/></p>\n"},
+		{"ScriptBlock", "<script>alert(1)</script>", ""},
+		{"InlineOnerror", "hi <img src=x onerror=alert(1)> there", "<p>hi <img src=\"x\"/> there</p>\n"},
+		{"HTMLBlockAttributes", "<div onclick=\"x\">\n*md*\n</div>", "\n*md*\n"},
+		{
+			"ScriptInTitle",
+			`[x](https://x.y "\"><script>alert(1)</script>")`,
+			"<p><a href=\"https://x.y\" title=\"&#34;&gt;&lt;script&gt;alert(1)&lt;/script&gt;\" rel=\"nofollow\">x</a></p>\n",
+		},
+		{"FenceInfoQuote", "```js\" onmouseover=\"alert(1)\nx\n```", "<pre><code>x\n</code></pre>\n"},
+		{"ScriptInFence", "```\n<script>alert(1)</script>\n```", "<pre><code>&lt;script&gt;alert(1)&lt;/script&gt;\n</code></pre>\n"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, sanitize.HTML(markdown.ToHTML(tc.in)))
+		})
+	}
+}
+
+// TestToHTMLAdversarial feeds the renderer inputs that make CommonMark
+// parsers backtrack, at the longest content a post may have.
+func TestToHTMLAdversarial(t *testing.T) {
+	const maxContent = 20000 // the content bound on post inputs
+
+	for _, pattern := range []string{"- ", "> ", "1. ", "> - ", "_a*", "*a_", "[a](", "[](", "[", "**a", "<a ", "`"} {
+		t.Run(pattern, func(t *testing.T) {
+			in := strings.Repeat(pattern, maxContent/len(pattern))
+
+			start := time.Now()
+			markdown.ToHTML(in)
+
+			assert.Less(t, time.Since(start), 2*time.Second)
+		})
+	}
+}
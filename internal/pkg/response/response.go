@@ -8,11 +8,49 @@ import (
 )
 
 type Response struct {
-	Success   bool        `json:"success"`
-	Message   string      `json:"message"`
-	Data      interface{} `json:"data,omitempty"`
-	Error     interface{} `json:"error,omitempty"`
-	RequestID string      `json:"request_id,omitempty"`
+	Success    bool        `json:"success"`
+	Message    string      `json:"message"`
+	Data       interface{} `json:"data,omitempty"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+	Error      interface{} `json:"error,omitempty"`
+	RequestID  string      `json:"request_id,omitempty"`
+}
+
+// Pagination describes where a list response sits in the full result set.
+// Cursor-paginated lists set NextCursor, offset-paginated lists set Page and PerPage.
+// Total is nil for lists that are too costly to count, like the feed, so it
+// is left out rather than reported as zero.
+type Pagination struct {
+	Total      *int64 `json:"total,omitempty"`
+	HasMore    bool   `json:"has_more"`
+	Limit      int    `json:"limit,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Page       int    `json:"page,omitempty"`
+	PerPage    int    `json:"per_page,omitempty"`
+}
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// PageSize returns the requested page size capped at MaxPageSize, or
+// DefaultPageSize when none was requested.
+func PageSize(n int) int {
+	if n <= 0 {
+		return DefaultPageSize
+	}
+	return min(n, MaxPageSize)
+}
+
+// PageBounds normalizes page and per_page and returns the matching row
+// offset.
+func PageBounds(page, perPage int) (int, int, int) {
+	if page <= 0 {
+		page = 1
+	}
+	perPage = PageSize(perPage)
+	return page, perPage, (page - 1) * perPage
 }
 
 func Success(c *gin.Context, code int, message string, data interface{}) {
@@ -25,6 +63,17 @@ func Success(c *gin.Context, code int, message string, data interface{}) {
 	})
 }
 
+func Paginated(c *gin.Context, code int, message string, data interface{}, pagination Pagination) {
+	reqID, _ := c.Get("RequestID")
+	c.JSON(code, Response{
+		Success:    true,
+		Message:    message,
+		Data:       data,
+		Pagination: &pagination,
+		RequestID:  reqID.(string),
+	})
+}
+
 func Error(c *gin.Context, code int, message string, err interface{}) {
 	reqID, _ := c.Get("RequestID")
 
@@ -0,0 +1,60 @@
+package response_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"post/internal/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaginated(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	render := func(pagination response.Pagination) string {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("RequestID", "test")
+		response.Paginated(c, http.StatusOK, "ok", []int{}, pagination)
+		return w.Body.String()
+	}
+
+	t.Run("EmptyListKeepsZeroTotal", func(t *testing.T) {
+		var total int64
+
+		body := render(response.Pagination{Total: &total, Page: 1, PerPage: 20})
+
+		assert.Contains(t, body, `"total":0`)
+	})
+
+	t.Run("UncountedListHasNoTotal", func(t *testing.T) {
+		body := render(response.Pagination{Limit: 20})
+
+		assert.NotContains(t, body, `"total"`)
+	})
+}
+
+func TestPageBounds(t *testing.T) {
+	tests := []struct {
+		name                          string
+		page, perPage                 int
+		wantPage, wantPerPage, offset int
+	}{
+		{"Defaults", 0, 0, 1, response.DefaultPageSize, 0},
+		{"Given", 3, 10, 3, 10, 20},
+		{"Capped", 2, 1000, 2, response.MaxPageSize, response.MaxPageSize},
+		{"Negative", -1, -5, 1, response.DefaultPageSize, 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			page, perPage, offset := response.PageBounds(tc.page, tc.perPage)
+
+			assert.Equal(t, tc.wantPage, page)
+			assert.Equal(t, tc.wantPerPage, perPage)
+			assert.Equal(t, tc.offset, offset)
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package sanitize
+
+import (
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// allowedTags are kept along with their allowed attributes. Any other element
+// is replaced by its children.
+var allowedTags = map[atom.Atom][]string{
+	atom.P: nil, atom.Br: nil, atom.Hr: nil, atom.Blockquote: nil, atom.Pre: nil,
+	atom.H1: nil, atom.H2: nil, atom.H3: nil, atom.H4: nil, atom.H5: nil, atom.H6: nil,
+	atom.Em: nil, atom.Strong: nil, atom.B: nil, atom.I: nil, atom.Del: nil, atom.S: nil,
+	atom.Sub: nil, atom.Sup: nil, atom.Mark: nil, atom.Kbd: nil,
+	atom.Ul: nil, atom.Li: nil, atom.Dl: nil, atom.Dt: nil, atom.Dd: nil,
+	atom.Table: nil, atom.Thead: nil, atom.Tbody: nil, atom.Tr: nil,
+
+	atom.Ol:   {"start"},
+	atom.Code: {"class"},
+	atom.A:    {"href", "title"},
+	atom.Img:  {"src", "alt", "title", "width", "height"},
+	atom.Th:   {"align", "colspan", "rowspan"},
+	atom.Td:   {"align", "colspan", "rowspan"},
+}
+
+// droppedTags are removed together with everything inside them.
+var droppedTags = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Iframe: true, atom.Frame: true, atom.Frameset: true,
+	atom.Object: true, atom.Embed: true, atom.Applet: true, atom.Noscript: true, atom.Template: true,
+	atom.Textarea: true, atom.Select: true, atom.Button: true, atom.Input: true, atom.Title: true,
+	atom.Head: true, atom.Meta: true, atom.Link: true, atom.Base: true, atom.Svg: true, atom.Math: true,
+}
+
+var (
+	numberRe   = regexp.MustCompile(`^[0-9]{1,4}$`)
+	languageRe = regexp.MustCompile(`^language-[A-Za-z0-9_+#.-]+$`)
+)
+
+// safeSchemes are the URL schemes allowed in href and src. Relative URLs have
+// no scheme and are allowed too.
+var safeSchemes = map[string]bool{"": true, "http": true, "https": true, "mailto": true}
+
+// HTML returns the fragment with everything outside an allowlist of
+// formatting tags removed. Scripts, styles, embedded content, event handler
+// and style attributes and javascript: style URLs never survive. Links are
+// marked rel="nofollow".
+func HTML(fragment string) string {
+	body := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(fragment), body)
+	if err != nil {
+		return html.EscapeString(fragment)
+	}
+
+	root := &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div}
+	for _, n := range nodes {
+		root.AppendChild(n)
+	}
+	clean(root)
+
+	var b strings.Builder
+	for n := root.FirstChild; n != nil; n = n.NextSibling {
+		if err := html.Render(&b, n); err != nil {
+			return ""
+		}
+	}
+	return b.String()
+}
+
+func clean(parent *html.Node) {
+	for n := parent.FirstChild; n != nil; {
+		next := n.NextSibling
+		switch {
+		case n.Type == html.TextNode:
+		case n.Type != html.ElementNode || n.Namespace != "" || droppedTags[n.DataAtom]:
+			parent.RemoveChild(n)
+		default:
+			clean(n)
+			if attrs, ok := allowedTags[n.DataAtom]; ok {
+				n.Attr = cleanAttrs(n.DataAtom, n.Attr, attrs)
+				break
+			}
+			// Unwrap the element, keeping its cleaned children
+			for c := n.FirstChild; c != nil; c = n.FirstChild {
+				n.RemoveChild(c)
+				parent.InsertBefore(c, n)
+			}
+			parent.RemoveChild(n)
+		}
+		n = next
+	}
+}
+
+func cleanAttrs(tag atom.Atom, attrs []html.Attribute, allowed []string) []html.Attribute {
+	var kept []html.Attribute
+	for _, attr := range attrs {
+		if attr.Namespace != "" || !slices.Contains(allowed, attr.Key) || !validAttr(attr.Key, attr.Val) {
+			continue
+		}
+		kept = append(kept, html.Attribute{Key: attr.Key, Val: attr.Val})
+	}
+	if tag == atom.A {
+		kept = append(kept, html.Attribute{Key: "rel", Val: "nofollow"})
+	}
+	return kept
+}
+
+func validAttr(key, val string) bool {
+	switch key {
+	case "href", "src":
+		return safeURL(val)
+	case "start", "width", "height", "colspan", "rowspan":
+		return numberRe.MatchString(val)
+	case "align":
+		return val == "left" || val == "right" || val == "center"
+	case "class":
+		return languageRe.MatchString(val)
+	}
+	return true
+}
+
+// safeURL rejects URLs with a scheme outside safeSchemes. URLs that do not
+// parse are rejected as well, which covers schemes disguised with control
+// characters.
+func safeURL(raw string) bool {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return false
+	}
+	return safeSchemes[strings.ToLower(u.Scheme)]
+}
@@ -0,0 +1,62 @@
+package sanitize_test
+
+import (
+	"testing"
+
+	"post/internal/pkg/sanitize"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		// Scripts and active content
+		{"Script", `<script>alert(1)</script>ok`, "ok"},
+		{"SplitScriptTag", `<scr<script>ipt>alert(1)</script>`, "ipt&gt;alert(1)"},
+		{"ScriptInSVG", `<svg><script>alert(1)</script></svg>after`, "after"},
+		{"MathML", `<math><mi xlink:href="javascript:alert(1)">x</mi></math>`, ""},
+		{"Iframe", `<iframe srcdoc="<script>alert(1)</script>"></iframe>x`, "x"},
+		{"Textarea", `<textarea><script>alert(1)</script></textarea>z`, "z"},
+		{"Comment", `<!-- comment -->text`, "text"},
+
+		// Attributes
+		{"EventHandler", `<img src=x onerror=alert(1)>`, `<img src="x"/>`},
+		{"Style", `<p style="color:red" class="x">hi</p>`, "<p>hi</p>"},
+		{"CodeClass", `<code class="language-go">x</code><code class="language-go x">y</code>`, `<code class="language-go">x</code><code>y</code>`},
+		{"OlStart", `<ol start="5"><li>a</li></ol><ol start="x"><li>b</li></ol>`, `<ol start="5"><li>a</li></ol><ol><li>b</li></ol>`},
+		{"TableCell", `<table><tr><td align="center" colspan="2">x</td></tr></table>`, `<table><tbody><tr><td align="center" colspan="2">x</td></tr></tbody></table>`},
+		{
+			"QuotedTitle",
+			`<a href="https://example.com" title='"><script>alert(1)</script>' onclick="x">x</a>`,
+			`<a href="https://example.com" title="&#34;&gt;&lt;script&gt;alert(1)&lt;/script&gt;" rel="nofollow">x</a>`,
+		},
+
+		// URLs
+		{"JavascriptURL", `<a href="javascript:alert(1)">x</a>`, `<a rel="nofollow">x</a>`},
+		{"MixedCaseScheme", `<a href="JaVaScRiPt:alert(1)">x</a>`, `<a rel="nofollow">x</a>`},
+		{"EntityEncodedScheme", `<a href="&#106;avascript:alert(1)">x</a>`, `<a rel="nofollow">x</a>`},
+		{"LeadingSpace", `<a href=" javascript:alert(1)">x</a>`, `<a rel="nofollow">x</a>`},
+		{"TabInScheme", "<a href=\"java\tscript:alert(1)\">x</a>", `<a rel="nofollow">x</a>`},
+		{"VBScript", `<a href="vbscript:x">v</a>`, `<a rel="nofollow">v</a>`},
+		{"DataURL", `<a href="data:text/html;base64,PHNjcmlwdD4=">x</a>`, `<a rel="nofollow">x</a>`},
+		{"DataImage", `<img src="data:image/svg+xml,<svg onload=alert(1)>">`, "<img/>"},
+		{
+			"SafeURLs",
+			`<a href="mailto:a@b.c">m</a><a href="/rel">r</a>`,
+			`<a href="mailto:a@b.c" rel="nofollow">m</a><a href="/rel" rel="nofollow">r</a>`,
+		},
+
+		// Unknown elements keep their content
+		{"Unwrap", `<div><span>kept</span></div>`, "kept"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, sanitize.HTML(tc.in))
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package slug
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxLength keeps slugs short enough for readable URLs.
+const MaxLength = 80
+
+// fallback is used when nothing of the input survives, e.g. a title written
+// only in a script we cannot transliterate.
+const fallback = "post"
+
+// transliterations covers letters that do not decompose into ASCII under
+// Unicode normalization.
+var transliterations = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'ł': "l", 'đ': "d", 'ð': "d", 'þ': "th", 'ı': "i",
+
+	// Cyrillic
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'ґ': "g", 'д': "d", 'е': "e", 'ё': "e", 'є': "ye",
+	'ж': "zh", 'з': "z", 'и': "i", 'і': "i", 'ї': "yi", 'й': "y", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh",
+	'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya",
+
+	// Greek
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i", 'θ': "th", 'ι': "i",
+	'κ': "k", 'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x", 'ο': "o", 'π': "p", 'ρ': "r", 'σ': "s",
+	'ς': "s", 'τ': "t", 'υ': "y", 'φ': "f", 'χ': "ch", 'ψ': "ps", 'ω': "o",
+}
+
+// Make turns s into a lowercase, URL-safe slug made of ASCII letters, digits
+// and single hyphens, e.g. "Crème Brûlée: à la carte!" becomes
+// "creme-brulee-a-la-carte".
+func Make(s string) string {
+	var b strings.Builder
+	hyphen := false
+	write := func(part string) {
+		for _, r := range part {
+			if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+				if hyphen && b.Len() > 0 {
+					b.WriteByte('-')
+				}
+				hyphen = false
+				b.WriteRune(r)
+			} else {
+				hyphen = true
+			}
+		}
+	}
+
+	// NFKD splits accented letters into a base letter and combining marks
+	for _, r := range norm.NFKD.String(strings.ToLower(s)) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		if t, ok := transliterations[r]; ok {
+			write(t)
+			continue
+		}
+		write(string(r))
+	}
+
+	slug := b.String()
+	if len(slug) > MaxLength {
+		slug = slug[:MaxLength]
+		// Prefer not to cut a word in half
+		if i := strings.LastIndexByte(slug, '-'); i > MaxLength/2 {
+			slug = slug[:i]
+		}
+		slug = strings.TrimSuffix(slug, "-")
+	}
+	if slug == "" {
+		return fallback
+	}
+	return slug
+}
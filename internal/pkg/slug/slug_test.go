@@ -0,0 +1,49 @@
+package slug_test
+
+import (
+	"strings"
+	"testing"
+
+	"post/internal/pkg/slug"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMake(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"Plain", "Hello World", "hello-world"},
+		{"Accents", "Crème Brûlée: à la carte!", "creme-brulee-a-la-carte"},
+		{"Punctuation", "  --Go, 1.24 -- released!!  ", "go-1-24-released"},
+		{"Transliterated", "Straße Øresund", "strasse-oresund"},
+		{"Cyrillic", "Привет мир", "privet-mir"},
+		{"Greek", "Καλημέρα", "kalimera"},
+		{"Ligature", "ﬁle", "file"},
+		{"Untransliterable", "こんにちは", "post"},
+		{"Empty", "", "post"},
+		{"PathCharacters", "../../etc/passwd?x=<script>", "etc-passwd-x-script"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, slug.Make(tc.in))
+		})
+	}
+
+	t.Run("CutsAtWordBoundary", func(t *testing.T) {
+		got := slug.Make(strings.Repeat("word ", 30))
+
+		assert.LessOrEqual(t, len(got), slug.MaxLength)
+		assert.True(t, strings.HasSuffix(got, "-word"))
+	})
+
+	t.Run("CutsLongWord", func(t *testing.T) {
+		got := slug.Make("a " + strings.Repeat("x", 200))
+
+		assert.Len(t, got, slug.MaxLength)
+		assert.False(t, strings.HasSuffix(got, "-"))
+	})
+}
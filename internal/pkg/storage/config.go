@@ -0,0 +1,28 @@
+package storage
+
+import (
+	"fmt"
+
+	"post/internal/pkg/config"
+)
+
+// New returns the backend selected by MEDIA_DRIVER. Locally stored files are
+// served under baseURL + "/media".
+func New(cfg config.MediaConfig, baseURL string) (Storage, error) {
+	switch cfg.Driver {
+	case "local":
+		return NewLocal(cfg.Dir, baseURL+"/media")
+	case "s3":
+		return NewS3(S3Config{
+			Endpoint:  cfg.S3.Endpoint,
+			Region:    cfg.S3.Region,
+			Bucket:    cfg.S3.Bucket,
+			AccessKey: cfg.S3.AccessKey,
+			SecretKey: cfg.S3.SecretKey,
+			UseSSL:    cfg.S3.UseSSL,
+			PublicURL: cfg.S3.PublicURL,
+		})
+	default:
+		return nil, fmt.Errorf("unknown media driver %q", cfg.Driver)
+	}
+}
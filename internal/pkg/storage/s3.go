@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Config points at an S3-compatible bucket, such as AWS S3 or MinIO.
+type S3Config struct {
+	// Endpoint is the host and optional port, without scheme.
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	UseSSL    bool
+	// PublicURL is where objects can be downloaded. It defaults to the
+	// bucket's path-style URL on Endpoint.
+	PublicURL string
+}
+
+type s3 struct {
+	client    *minio.Client
+	bucket    string
+	publicURL string
+}
+
+// NewS3 returns a storage backed by the configured bucket. The bucket must
+// already exist.
+func NewS3(cfg S3Config) (Storage, error) {
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	publicURL := strings.TrimRight(cfg.PublicURL, "/")
+	if publicURL == "" {
+		u := url.URL{Scheme: "http", Host: cfg.Endpoint, Path: "/" + cfg.Bucket}
+		if cfg.UseSSL {
+			u.Scheme = "https"
+		}
+		publicURL = u.String()
+	}
+	return &s3{client, cfg.Bucket, publicURL}, nil
+}
+
+func (s *s3) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.PutObject(ctx, s.bucket, key, r, size, minio.PutObjectOptions{ContentType: contentType})
+	return err
+}
+
+func (s *s3) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+	// GetObject is lazy, Stat surfaces a missing object up front
+	obj, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := obj.Stat(); err != nil {
+		obj.Close()
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return obj, nil
+}
+
+// Delete removes the object. S3 reports success for missing objects too.
+func (s *s3) Delete(ctx context.Context, key string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+	return s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+}
+
+func (s *s3) URL(key string) string {
+	return s.publicURL + "/" + key
+}
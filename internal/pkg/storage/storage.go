@@ -0,0 +1,33 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path"
+	"strings"
+)
+
+var (
+	ErrNotFound   = errors.New("object not found")
+	ErrInvalidKey = errors.New("invalid object key")
+)
+
+// Storage keeps uploaded files. Keys are slash separated relative paths like
+// "3/4f1c2a.png".
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	// URL is where clients can download the object.
+	URL(key string) string
+}
+
+// cleanKey rejects keys that are absolute or would escape the storage root.
+func cleanKey(key string) (string, error) {
+	cleaned := path.Clean(key)
+	if key == "" || cleaned != key || path.IsAbs(cleaned) || cleaned == "." || strings.HasPrefix(cleaned, "../") || cleaned == ".." {
+		return "", ErrInvalidKey
+	}
+	return cleaned, nil
+}
@@ -1,9 +1,14 @@
 package post
 
 import (
+	"errors"
 	"net/http"
+	"net/url"
 	"strconv"
 
+	"post/internal/entity"
+	pkgdb "post/internal/pkg/database"
+	"post/internal/pkg/diff"
 	"post/internal/pkg/response"
 
 	"github.com/gin-gonic/gin"
@@ -28,21 +33,82 @@ func (h *Handler) CreatePost(c *gin.Context) {
 
 	post, err := h.service.Create(userID, input)
 	if err != nil {
+		if errors.Is(err, ErrInvalidSchedule) {
+			response.Error(c, http.StatusBadRequest, "Invalid input", err.Error())
+			return
+		}
 		response.Error(c, http.StatusInternalServerError, "Failed to create post", err.Error())
 		return
 	}
 
-	response.Success(c, http.StatusCreated, "Post created successfully", post)
+	response.Success(c, http.StatusCreated, "Post created successfully", NewView(post, ViewerFrom(c)))
 }
 
 func (h *Handler) GetAllPosts(c *gin.Context) {
-	posts, err := h.service.GetAll()
+	var query ListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid query", err)
+		return
+	}
+
+	page, err := h.service.List(ViewerFrom(c), query)
 	if err != nil {
+		if errors.Is(err, ErrInvalidCursor) {
+			response.Error(c, http.StatusBadRequest, "Invalid cursor", nil)
+			return
+		}
 		response.Error(c, http.StatusInternalServerError, "Failed to fetch posts", err.Error())
 		return
 	}
 
-	response.Success(c, http.StatusOK, "Posts retrieved", posts)
+	response.Paginated(c, http.StatusOK, "Posts retrieved", NewViews(page.Posts, ViewerFrom(c)), paginationOf(page))
+}
+
+// GetFeed lists the posts of the authors the caller follows.
+func (h *Handler) GetFeed(c *gin.Context) {
+	var query FeedQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid query", err)
+		return
+	}
+
+	page, err := h.service.Feed(ViewerFrom(c), query)
+	if err != nil {
+		if errors.Is(err, ErrInvalidCursor) {
+			response.Error(c, http.StatusBadRequest, "Invalid cursor", nil)
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to fetch feed", err.Error())
+		return
+	}
+
+	// The feed is never counted, so it has no total
+	response.Paginated(c, http.StatusOK, "Feed retrieved", NewViews(page.Posts, ViewerFrom(c)), response.Pagination{
+		HasMore:    page.HasMore,
+		Limit:      page.Limit,
+		NextCursor: page.NextCursor,
+	})
+}
+
+func (h *Handler) SearchPosts(c *gin.Context) {
+	var query SearchQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid query", err)
+		return
+	}
+
+	page, err := h.service.Search(ViewerFrom(c), query)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to search posts", err.Error())
+		return
+	}
+
+	response.Paginated(c, http.StatusOK, "Search results retrieved", newSearchResultViews(page.Results, ViewerFrom(c)), response.Pagination{
+		Total:   &page.Total,
+		HasMore: page.HasMore,
+		Page:    page.Page,
+		PerPage: page.Limit,
+	})
 }
 
 func (h *Handler) GetPostByID(c *gin.Context) {
@@ -53,11 +119,297 @@ func (h *Handler) GetPostByID(c *gin.Context) {
 		return
 	}
 
-	post, err := h.service.GetByID(uint(id))
+	post, err := h.service.GetByID(uint(id), ViewerFrom(c))
 	if err != nil {
 		response.Error(c, http.StatusNotFound, "Post not found", err.Error())
 		return
 	}
 
-	response.Success(c, http.StatusOK, "Post retrieved", post)
+	response.Success(c, http.StatusOK, "Post retrieved", NewView(post, ViewerFrom(c)))
+}
+
+// GetPostBySlug serves a post by its slug. Old slugs left behind by title
+// changes redirect permanently to the current one.
+func (h *Handler) GetPostBySlug(c *gin.Context) {
+	slug := c.Param("slug")
+	post, err := h.service.GetBySlug(slug, ViewerFrom(c))
+	if err != nil {
+		if errors.Is(err, pkgdb.ErrRecordNotFound) {
+			response.Error(c, http.StatusNotFound, "Post not found", err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to retrieve post", err.Error())
+		return
+	}
+
+	if post.Slug != slug {
+		c.Redirect(http.StatusMovedPermanently, "/api/posts/by-slug/"+url.PathEscape(post.Slug))
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Post retrieved", NewView(post, ViewerFrom(c)))
+}
+
+// ReplacePost replaces the post's title, content, format and tags, so tags
+// left out are removed. The status only moves through the lifecycle
+// endpoints, so status and publish_at are rejected here.
+func (h *Handler) ReplacePost(c *gin.Context) {
+	var input CreatePostInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+	if input.Status != "" || input.PublishAt != nil {
+		response.Error(c, http.StatusUnprocessableEntity, "Invalid input", "status and publish_at are changed through the publish, unpublish, archive and schedule endpoints")
+		return
+	}
+
+	tags := input.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	replacement := UpdatePostInput{Title: &input.Title, Content: &input.Content, Tags: &tags}
+	if input.ContentFormat != "" {
+		replacement.ContentFormat = &input.ContentFormat
+	}
+	h.update(c, replacement)
+}
+
+func (h *Handler) UpdatePost(c *gin.Context) {
+	var input UpdatePostInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+
+	h.update(c, input)
+}
+
+func (h *Handler) update(c *gin.Context, input UpdatePostInput) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid ID", err.Error())
+		return
+	}
+
+	post, err := h.service.Update(uint(id), ViewerFrom(c), input)
+	if err != nil {
+		writeOwnershipError(c, err, "Failed to update post")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Post updated successfully", NewView(post, ViewerFrom(c)))
+}
+
+func (h *Handler) DeletePost(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid ID", err.Error())
+		return
+	}
+
+	if err := h.service.DeleteOwned(uint(id), ViewerFrom(c)); err != nil {
+		writeOwnershipError(c, err, "Failed to delete post")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Post deleted successfully", nil)
+}
+
+func paginationOf(page *PostPage) response.Pagination {
+	pagination := response.Pagination{
+		Total:   &page.Total,
+		HasMore: page.HasMore,
+	}
+	if page.Page > 0 {
+		pagination.Page = page.Page
+		pagination.PerPage = page.Limit
+	} else {
+		pagination.Limit = page.Limit
+		pagination.NextCursor = page.NextCursor
+	}
+	return pagination
+}
+
+func (h *Handler) PublishPost(c *gin.Context) {
+	h.setStatus(c, entity.PostStatusPublished, "Post published")
+}
+
+func (h *Handler) UnpublishPost(c *gin.Context) {
+	h.setStatus(c, entity.PostStatusDraft, "Post moved back to draft")
+}
+
+func (h *Handler) ArchivePost(c *gin.Context) {
+	h.setStatus(c, entity.PostStatusArchived, "Post archived")
+}
+
+func (h *Handler) setStatus(c *gin.Context, status entity.PostStatus, message string) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid ID", err.Error())
+		return
+	}
+
+	post, err := h.service.SetStatus(uint(id), ViewerFrom(c), status)
+	if err != nil {
+		writeOwnershipError(c, err, "Failed to update post status")
+		return
+	}
+
+	response.Success(c, http.StatusOK, message, NewView(post, ViewerFrom(c)))
+}
+
+func (h *Handler) SchedulePost(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid ID", err.Error())
+		return
+	}
+
+	var input ScheduleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+
+	post, err := h.service.Schedule(uint(id), ViewerFrom(c), input.PublishAt)
+	if err != nil {
+		if errors.Is(err, ErrInvalidSchedule) {
+			response.Error(c, http.StatusBadRequest, "Invalid input", err.Error())
+			return
+		}
+		writeOwnershipError(c, err, "Failed to schedule post")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Post scheduled", NewView(post, ViewerFrom(c)))
+}
+
+func (h *Handler) GetRevisions(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid ID", err.Error())
+		return
+	}
+
+	revisions, err := h.service.GetRevisions(uint(id), ViewerFrom(c))
+	if err != nil {
+		writeOwnershipError(c, err, "Failed to fetch revisions")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Revisions retrieved", revisions)
+}
+
+func (h *Handler) DiffRevisions(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid ID", err.Error())
+		return
+	}
+
+	var query DiffQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid query", err)
+		return
+	}
+
+	unified, err := h.service.DiffRevisions(uint(id), ViewerFrom(c), query.From, query.To)
+	if err != nil {
+		if errors.Is(err, diff.ErrTooLarge) {
+			response.Error(c, http.StatusUnprocessableEntity, "Revisions too large to diff", err.Error())
+			return
+		}
+		writeOwnershipError(c, err, "Failed to diff revisions")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Diff generated", gin.H{
+		"from": query.From,
+		"to":   query.To,
+		"diff": unified,
+	})
+}
+
+func (h *Handler) RestoreRevision(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid ID", err.Error())
+		return
+	}
+
+	revStr := c.Param("rev")
+	rev, err := strconv.Atoi(revStr)
+	if err != nil || rev < 1 {
+		response.Error(c, http.StatusBadRequest, "Invalid revision", nil)
+		return
+	}
+
+	post, err := h.service.RestoreRevision(uint(id), ViewerFrom(c), rev)
+	if err != nil {
+		writeOwnershipError(c, err, "Failed to restore revision")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Revision restored", NewView(post, ViewerFrom(c)))
+}
+
+func (h *Handler) AddReaction(c *gin.Context) {
+	h.react(c, h.service.React)
+}
+
+func (h *Handler) RemoveReaction(c *gin.Context) {
+	h.react(c, h.service.Unreact)
+}
+
+func (h *Handler) react(c *gin.Context, apply func(uint, Viewer, entity.ReactionKind) (*ReactionSummary, error)) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid ID", err.Error())
+		return
+	}
+
+	summary, err := apply(uint(id), ViewerFrom(c), entity.ReactionKind(c.Param("kind")))
+	if err != nil {
+		if errors.Is(err, ErrInvalidReaction) {
+			response.Error(c, http.StatusBadRequest, "Invalid reaction", err.Error())
+			return
+		}
+		writeOwnershipError(c, err, "Failed to update reaction")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Reactions updated", summary)
+}
+
+// ViewerFrom builds the Viewer from what auth.Middleware put in the context.
+// Requests without a token yield the anonymous Viewer.
+func ViewerFrom(c *gin.Context) Viewer {
+	var viewer Viewer
+	if userID, ok := c.Get("userID"); ok {
+		viewer.UserID, _ = userID.(uint)
+	}
+	if role, ok := c.Get("role"); ok {
+		viewer.Role, _ = role.(entity.Role)
+	}
+	return viewer
+}
+
+func writeOwnershipError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, pkgdb.ErrRecordNotFound):
+		response.Error(c, http.StatusNotFound, "Not found", nil)
+	case errors.Is(err, ErrForbidden):
+		response.Error(c, http.StatusForbidden, "Forbidden", err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, message, err.Error())
+	}
 }
@@ -0,0 +1,101 @@
+package post_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"post/internal/entity"
+	"post/internal/post"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestReplacePost(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	setup := func() (*MockRepository, *MockCache, *gin.Engine) {
+		mockRepo := new(MockRepository)
+		mockCache := new(MockCache)
+		handler := post.NewHandler(post.NewService(mockRepo, mockCache))
+
+		r := gin.New()
+		r.PUT("/posts/:id", func(c *gin.Context) {
+			c.Set("RequestID", "test")
+			c.Set("userID", uint(1))
+			c.Set("role", entity.RoleUser)
+		}, handler.ReplacePost)
+		return mockRepo, mockCache, r
+	}
+	put := func(r *gin.Engine, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, "/posts/1", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("ReplacesTags", func(t *testing.T) {
+		mockRepo, mockCache, r := setup()
+		existing := &entity.Post{ID: 1, UserID: 1, Title: "Title", Content: "Content", Tags: []entity.Tag{{Name: "old"}}}
+
+		mockRepo.On("FindByID", uint(1)).Return(existing, nil)
+		mockRepo.On("Update", mock.MatchedBy(func(p *entity.Post) bool {
+			return len(p.Tags) == 1 && p.Tags[0].Name == "new"
+		})).Return(nil)
+		mockCache.On("Delete", "all_posts").Return()
+		mockCache.On("DeletePrefix", "posts:").Return()
+
+		w := put(r, `{"title": "Title", "content": "Content", "tags": ["new"]}`)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("MissingTagsClearsThem", func(t *testing.T) {
+		mockRepo, mockCache, r := setup()
+		existing := &entity.Post{ID: 1, UserID: 1, Title: "Title", Content: "Content", Tags: []entity.Tag{{Name: "old"}}}
+
+		mockRepo.On("FindByID", uint(1)).Return(existing, nil)
+		mockRepo.On("Update", mock.MatchedBy(func(p *entity.Post) bool {
+			return len(p.Tags) == 0
+		})).Return(nil)
+		mockCache.On("Delete", "all_posts").Return()
+		mockCache.On("DeletePrefix", "posts:").Return()
+
+		w := put(r, `{"title": "Title", "content": "Content"}`)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("RejectsStatus", func(t *testing.T) {
+		mockRepo, _, r := setup()
+
+		w := put(r, `{"title": "Title", "content": "Content", "status": "published"}`)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		mockRepo.AssertNotCalled(t, "FindByID", mock.Anything)
+	})
+
+	t.Run("RejectsLongContent", func(t *testing.T) {
+		mockRepo, _, r := setup()
+
+		w := put(r, `{"title": "Title", "content": "`+strings.Repeat("a", 20001)+`"}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockRepo.AssertNotCalled(t, "FindByID", mock.Anything)
+	})
+
+	t.Run("RejectsPublishAt", func(t *testing.T) {
+		mockRepo, _, r := setup()
+
+		w := put(r, `{"title": "Title", "content": "Content", "publish_at": "2030-01-01T00:00:00Z"}`)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		mockRepo.AssertNotCalled(t, "FindByID", mock.Anything)
+	})
+}
@@ -0,0 +1,118 @@
+package post
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"post/internal/entity"
+	"post/internal/pkg/markdown"
+	"post/internal/pkg/sanitize"
+
+	nethtml "golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const (
+	// excerptLength is the excerpt size in characters, before the ellipsis.
+	excerptLength  = 160
+	wordsPerMinute = 200
+)
+
+var paragraphBreakRe = regexp.MustCompile(`\n[ \t]*\n`)
+
+// blockTags separate words in the text an excerpt is cut from.
+var blockTags = map[atom.Atom]bool{
+	atom.P: true, atom.Br: true, atom.Hr: true, atom.Blockquote: true, atom.Pre: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Ul: true, atom.Ol: true, atom.Li: true, atom.Dl: true, atom.Dt: true, atom.Dd: true,
+	atom.Table: true, atom.Tr: true, atom.Th: true, atom.Td: true,
+}
+
+// render fills in the fields derived from the post's content: the sanitized
+// HTML, the excerpt and the reading time.
+func render(post *entity.Post) {
+	if post.ContentFormat == "" {
+		post.ContentFormat = entity.ContentFormatPlain
+	}
+
+	var raw string
+	switch post.ContentFormat {
+	case entity.ContentFormatMarkdown:
+		raw = markdown.ToHTML(post.Content)
+	default:
+		raw = plainToHTML(post.Content)
+	}
+	post.ContentHTML = sanitize.HTML(raw)
+
+	text := textOf(post.ContentHTML)
+	post.Excerpt = excerpt(text)
+	post.ReadingTime = readingTime(text)
+}
+
+// plainToHTML turns blank-line separated text into paragraphs, keeping single
+// line breaks.
+func plainToHTML(content string) string {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	var paragraphs []string
+	for _, p := range paragraphBreakRe.Split(content, -1) {
+		p = strings.Trim(p, " \t\n")
+		if p == "" {
+			continue
+		}
+		paragraphs = append(paragraphs, "<p>"+strings.ReplaceAll(html.EscapeString(p), "\n", "<br>\n")+"</p>")
+	}
+	return strings.Join(paragraphs, "\n")
+}
+
+// textOf returns the visible text of an HTML fragment with whitespace
+// collapsed.
+func textOf(fragment string) string {
+	body := &nethtml.Node{Type: nethtml.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := nethtml.ParseFragment(strings.NewReader(fragment), body)
+	if err != nil {
+		return ""
+	}
+
+	var b strings.Builder
+	var walk func(n *nethtml.Node)
+	walk = func(n *nethtml.Node) {
+		if n.Type == nethtml.TextNode {
+			b.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+		if blockTags[n.DataAtom] {
+			b.WriteByte(' ')
+		}
+	}
+	for _, n := range nodes {
+		walk(n)
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// excerpt shortens text to excerptLength characters, cutting at a word
+// boundary unless the first word alone is too long.
+func excerpt(text string) string {
+	runes := []rune(text)
+	if len(runes) <= excerptLength {
+		return text
+	}
+
+	cut := string(runes[:excerptLength+1])
+	cut = strings.TrimRightFunc(cut, func(r rune) bool { return !unicode.IsSpace(r) })
+	cut = strings.TrimRightFunc(cut, unicode.IsSpace)
+	if cut == "" {
+		cut = string(runes[:excerptLength])
+	}
+	return cut + "…"
+}
+
+// readingTime estimates the minutes needed to read text, at least one.
+func readingTime(text string) int {
+	minutes := (len(strings.Fields(text)) + wordsPerMinute - 1) / wordsPerMinute
+	return max(minutes, 1)
+}
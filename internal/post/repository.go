@@ -1,18 +1,82 @@
 package post
 
 import (
+	"fmt"
+	"time"
+
 	"post/internal/entity"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Repository interface {
 	Create(post *entity.Post) error
 	FindAll() ([]entity.Post, error)
+	FindPage(filter Filter, after *Cursor, offset, limit int) ([]entity.Post, error)
+	Count(filter Filter) (int64, error)
+	Search(query string, offset, limit int) ([]SearchResult, error)
+	CountSearch(query string) (int64, error)
+	FindPublishedLinks(offset, limit int) ([]Link, error)
 	FindByID(id uint) (*entity.Post, error)
-	FindByUserID(userID uint) ([]entity.Post, error)
+	FindBySlug(slug string) (*entity.Post, error)
+	FindPostIDByOldSlug(slug string) (uint, error)
+	FindTakenSlugs(base string, exceptPostID uint) ([]string, error)
 	Update(post *entity.Post) error
+	UpdateWithRevision(post *entity.Post, editorID uint) error
+	FindRevisions(postID uint) ([]entity.PostRevision, error)
+	FindRevision(postID uint, number int) (*entity.PostRevision, error)
 	Delete(id uint) error
+	AddReaction(reaction *entity.PostReaction) error
+	RemoveReaction(postID, userID uint, kind entity.ReactionKind) error
+	FindReactions(postIDs []uint, userID uint) (map[uint]ReactionSummary, error)
+	PublishDue(now time.Time, limit int) ([]uint, error)
+	CountPublishedSince(since time.Time) (int64, error)
+}
+
+// headlineOptions controls the ts_headline snippets returned by Search.
+const headlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=30, MinWords=10"
+
+// SearchResult is a post matched by full-text search, with its relevance and
+// a snippet of the content where matches are wrapped in <mark> tags.
+type SearchResult struct {
+	entity.Post
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
+
+// Link is the part of a published post needed to link to it.
+type Link struct {
+	ID        uint
+	Slug      string
+	UpdatedAt time.Time
+}
+
+// ReactionSummary is the reactions on a post: how many of each kind, and
+// which kinds the viewer left.
+type ReactionSummary struct {
+	Counts map[entity.ReactionKind]int64 `json:"counts"`
+	Mine   []entity.ReactionKind         `json:"mine"`
+}
+
+// Filter narrows the posts returned by FindPage and Count. Only published
+// posts are returned unless AllStatuses is set; ViewerID additionally
+// includes that user's own unpublished posts. AuthorID keeps a single
+// author's posts and FollowedBy the posts of authors that user follows.
+type Filter struct {
+	Tag         string
+	ViewerID    uint
+	AllStatuses bool
+	AuthorID    uint
+	FollowedBy  uint
+}
+
+// key identifies the list narrowing part of the filter in cache keys.
+func (f Filter) key() string {
+	if f.AuthorID == 0 {
+		return f.Tag
+	}
+	return fmt.Sprintf("%s:author%d", f.Tag, f.AuthorID)
 }
 
 type repository struct {
@@ -24,31 +88,350 @@ func NewRepository(db *gorm.DB) Repository {
 }
 
 func (r *repository) Create(post *entity.Post) error {
-	return r.db.Create(post).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		tags, err := findOrCreateTags(tx, post.Tags)
+		if err != nil {
+			return err
+		}
+		post.Tags = tags
+		if err := tx.Omit("Tags.*").Create(post).Error; err != nil {
+			return err
+		}
+		return tx.Create(&entity.PostRevision{
+			PostID:   post.ID,
+			Number:   1,
+			Title:    post.Title,
+			Content:  post.Content,
+			EditorID: post.UserID,
+		}).Error
+	})
+}
+
+// visible scopes the query to posts whose author has not been deleted.
+func (r *repository) visible() *gorm.DB {
+	return r.db.Model(&entity.Post{}).Joins("JOIN users ON posts.user_id = users.id").Where("users.deleted_at IS NULL")
+}
+
+func (r *repository) published() *gorm.DB {
+	return r.visible().Where("posts.status = ?", entity.PostStatusPublished)
+}
+
+// filtered applies filter on top of visible.
+func (r *repository) filtered(filter Filter) *gorm.DB {
+	query := r.visible()
+	switch {
+	case filter.AllStatuses:
+	case filter.ViewerID != 0:
+		query = query.Where("(posts.status = ? OR posts.user_id = ?)", entity.PostStatusPublished, filter.ViewerID)
+	default:
+		query = query.Where("posts.status = ?", entity.PostStatusPublished)
+	}
+	if filter.Tag != "" {
+		query = query.Where("EXISTS (SELECT 1 FROM post_tags JOIN tags ON tags.id = post_tags.tag_id WHERE post_tags.post_id = posts.id AND tags.name = ?)", filter.Tag)
+	}
+	if filter.AuthorID != 0 {
+		query = query.Where("posts.user_id = ?", filter.AuthorID)
+	}
+	if filter.FollowedBy != 0 {
+		// A semi-join on the follows primary key lets Postgres walk posts
+		// newest first and stop after one page however many authors are
+		// followed, instead of merging every author's posts.
+		query = query.Where("EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = ? AND follows.followee_id = posts.user_id)", filter.FollowedBy)
+	}
+	return query
 }
 
 func (r *repository) FindAll() ([]entity.Post, error) {
 	var posts []entity.Post
-	err := r.db.Preload("User").Joins("JOIN users ON posts.user_id = users.id").Where("users.deleted_at IS NULL").Find(&posts).Error
+	err := r.visible().Preload("User.Profile").Preload("Tags").Find(&posts).Error
 	return posts, err
 }
 
+// FindPage returns posts newest first. When after is set the page starts
+// right after that cursor (keyset pagination), otherwise offset is used.
+func (r *repository) FindPage(filter Filter, after *Cursor, offset, limit int) ([]entity.Post, error) {
+	var posts []entity.Post
+	query := r.filtered(filter).Preload("User.Profile").Preload("Tags").Order("posts.created_at DESC, posts.id DESC").Limit(limit)
+	if after != nil {
+		query = query.Where("(posts.created_at, posts.id) < (?, ?)", after.CreatedAt, after.ID)
+	} else if offset > 0 {
+		query = query.Offset(offset)
+	}
+	err := query.Find(&posts).Error
+	return posts, err
+}
+
+func (r *repository) Count(filter Filter) (int64, error) {
+	var total int64
+	err := r.filtered(filter).Count(&total).Error
+	return total, err
+}
+
+// Search ranks posts matching a web-style query ("go -java", "\"exact phrase\"")
+// and highlights the matching parts of their content.
+func (r *repository) Search(query string, offset, limit int) ([]SearchResult, error) {
+	var rows []struct {
+		ID      uint
+		Rank    float64
+		Snippet string
+	}
+	err := r.published().
+		Select("posts.id, ts_rank(posts.search_vector, q) AS rank, ts_headline('english', posts.content, q, ?) AS snippet", headlineOptions).
+		Joins("CROSS JOIN websearch_to_tsquery('english', ?) AS q", query).
+		Where("posts.search_vector @@ q").
+		Order("rank DESC, posts.id DESC").
+		Offset(offset).
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+
+	ids := make([]uint, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+	}
+
+	var posts []entity.Post
+	if err := r.db.Preload("User.Profile").Preload("Tags").Where("id IN ?", ids).Find(&posts).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]entity.Post, len(posts))
+	for _, p := range posts {
+		byID[p.ID] = p
+	}
+
+	// Keep the rank order of the first query
+	results := make([]SearchResult, 0, len(rows))
+	for _, row := range rows {
+		if p, ok := byID[row.ID]; ok {
+			results = append(results, SearchResult{Post: p, Rank: row.Rank, Snippet: row.Snippet})
+		}
+	}
+	return results, nil
+}
+
+func (r *repository) CountSearch(query string) (int64, error) {
+	var total int64
+	err := r.published().Where("posts.search_vector @@ websearch_to_tsquery('english', ?)", query).Count(&total).Error
+	return total, err
+}
+
+// FindPublishedLinks pages through published posts in ID order.
+func (r *repository) FindPublishedLinks(offset, limit int) ([]Link, error) {
+	var links []Link
+	err := r.published().
+		Select("posts.id, posts.slug, posts.updated_at").
+		Order("posts.id").
+		Offset(offset).
+		Limit(limit).
+		Scan(&links).Error
+	return links, err
+}
+
 func (r *repository) FindByID(id uint) (*entity.Post, error) {
 	var post entity.Post
-	err := r.db.First(&post, id).Error
+	err := r.db.Preload("Tags").First(&post, id).Error
 	return &post, err
 }
 
-func (r *repository) FindByUserID(userID uint) ([]entity.Post, error) {
-	var posts []entity.Post
-	err := r.db.Where("user_id = ?", userID).Find(&posts).Error
-	return posts, err
+func (r *repository) FindBySlug(slug string) (*entity.Post, error) {
+	var post entity.Post
+	err := r.db.Preload("Tags").Where("slug = ?", slug).First(&post).Error
+	return &post, err
+}
+
+// FindPostIDByOldSlug returns the post that used to be reachable under slug.
+func (r *repository) FindPostIDByOldSlug(slug string) (uint, error) {
+	var old entity.PostSlug
+	err := r.db.Where("slug = ?", slug).First(&old).Error
+	return old.PostID, err
+}
+
+// FindTakenSlugs returns base and every base-N slug in use by other posts,
+// currently or as an old slug. Deleted posts keep their slugs reserved.
+func (r *repository) FindTakenSlugs(base string, exceptPostID uint) ([]string, error) {
+	var slugs []string
+	err := r.db.Raw(`SELECT slug FROM posts WHERE (slug = ? OR slug LIKE ?) AND id <> ?
+		UNION
+		SELECT slug FROM post_slugs WHERE (slug = ? OR slug LIKE ?) AND post_id <> ?`,
+		base, base+"-%", exceptPostID,
+		base, base+"-%", exceptPostID,
+	).Scan(&slugs).Error
+	return slugs, err
 }
 
 func (r *repository) Update(post *entity.Post) error {
-	return r.db.Save(post).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return save(tx, post)
+	})
+}
+
+// UpdateWithRevision saves the post and records its new title and content
+// as the next revision.
+func (r *repository) UpdateWithRevision(post *entity.Post, editorID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the post so concurrent edits get consecutive revision numbers
+		var current entity.Post
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "slug").First(&current, post.ID).Error; err != nil {
+			return err
+		}
+		if err := save(tx, post); err != nil {
+			return err
+		}
+		if current.Slug != post.Slug {
+			if err := keepOldSlug(tx, post.ID, current.Slug, post.Slug); err != nil {
+				return err
+			}
+		}
+
+		var last int
+		if err := tx.Model(&entity.PostRevision{}).Where("post_id = ?", post.ID).Select("COALESCE(MAX(number), 0)").Scan(&last).Error; err != nil {
+			return err
+		}
+		return tx.Create(&entity.PostRevision{
+			PostID:   post.ID,
+			Number:   last + 1,
+			Title:    post.Title,
+			Content:  post.Content,
+			EditorID: editorID,
+		}).Error
+	})
+}
+
+// FindRevisions returns the post's revisions, newest first.
+func (r *repository) FindRevisions(postID uint) ([]entity.PostRevision, error) {
+	var revisions []entity.PostRevision
+	err := r.db.Where("post_id = ?", postID).Order("number DESC").Find(&revisions).Error
+	return revisions, err
+}
+
+func (r *repository) FindRevision(postID uint, number int) (*entity.PostRevision, error) {
+	var revision entity.PostRevision
+	err := r.db.Where("post_id = ? AND number = ?", postID, number).First(&revision).Error
+	return &revision, err
 }
 
 func (r *repository) Delete(id uint) error {
 	return r.db.Delete(&entity.Post{}, id).Error
 }
+
+// AddReaction stores the reaction unless the user already left it.
+func (r *repository) AddReaction(reaction *entity.PostReaction) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(reaction).Error
+}
+
+func (r *repository) RemoveReaction(postID, userID uint, kind entity.ReactionKind) error {
+	return r.db.Where("post_id = ? AND user_id = ? AND kind = ?", postID, userID, kind).
+		Delete(&entity.PostReaction{}).Error
+}
+
+// FindReactions summarizes the reactions on all given posts in one query.
+// Posts without reactions are missing from the result.
+func (r *repository) FindReactions(postIDs []uint, userID uint) (map[uint]ReactionSummary, error) {
+	summaries := make(map[uint]ReactionSummary)
+	if len(postIDs) == 0 {
+		return summaries, nil
+	}
+
+	var rows []struct {
+		PostID uint
+		Kind   entity.ReactionKind
+		Count  int64
+		Mine   bool
+	}
+	err := r.db.Model(&entity.PostReaction{}).
+		Select("post_id, kind, COUNT(*) AS count, BOOL_OR(user_id = ?) AS mine", userID).
+		Where("post_id IN ?", postIDs).
+		Group("post_id, kind").
+		Order("post_id, kind").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		summary, ok := summaries[row.PostID]
+		if !ok {
+			summary.Counts = make(map[entity.ReactionKind]int64)
+		}
+		summary.Counts[row.Kind] = row.Count
+		if row.Mine {
+			summary.Mine = append(summary.Mine, row.Kind)
+		}
+		summaries[row.PostID] = summary
+	}
+	return summaries, nil
+}
+
+// save writes the post and replaces its tags.
+func save(tx *gorm.DB, post *entity.Post) error {
+	tags, err := findOrCreateTags(tx, post.Tags)
+	if err != nil {
+		return err
+	}
+	post.Tags = tags
+	if err := tx.Omit("Tags").Save(post).Error; err != nil {
+		return err
+	}
+	return tx.Model(post).Association("Tags").Replace(post.Tags)
+}
+
+// keepOldSlug records oldSlug as a redirect to the post. If the post is
+// taking back one of its own old slugs, that redirect is dropped.
+func keepOldSlug(tx *gorm.DB, postID uint, oldSlug, newSlug string) error {
+	if err := tx.Where("post_id = ? AND slug = ?", postID, newSlug).Delete(&entity.PostSlug{}).Error; err != nil {
+		return err
+	}
+	return tx.Create(&entity.PostSlug{PostID: postID, Slug: oldSlug}).Error
+}
+
+// findOrCreateTags resolves tags by name, creating the ones that do not exist yet.
+func findOrCreateTags(tx *gorm.DB, tags []entity.Tag) ([]entity.Tag, error) {
+	if len(tags) == 0 {
+		return []entity.Tag{}, nil
+	}
+
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+	}
+
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&tags).Error; err != nil {
+		return nil, err
+	}
+
+	var resolved []entity.Tag
+	err := tx.Where("name IN ?", names).Find(&resolved).Error
+	return resolved, err
+}
+
+// PublishDue publishes up to limit scheduled posts whose publish_at has
+// passed and returns their IDs. Rows locked by another replica are skipped,
+// so concurrent schedulers never publish the same post twice.
+func (r *repository) PublishDue(now time.Time, limit int) ([]uint, error) {
+	var ids []uint
+	err := r.db.Raw(`UPDATE posts
+		SET status = ?, published_at = COALESCE(published_at, publish_at), updated_at = ?
+		WHERE id IN (
+			SELECT id FROM posts
+			WHERE status = ? AND publish_at <= ? AND deleted_at IS NULL
+			ORDER BY publish_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id`,
+		entity.PostStatusPublished, now, entity.PostStatusScheduled, now, limit,
+	).Scan(&ids).Error
+	return ids, err
+}
+
+// CountPublishedSince counts scheduled posts that went live after since,
+// whichever process published them.
+func (r *repository) CountPublishedSince(since time.Time) (int64, error) {
+	var total int64
+	err := r.db.Model(&entity.Post{}).
+		Where("status = ? AND publish_at IS NOT NULL AND updated_at > ?", entity.PostStatusPublished, since).
+		Count(&total).Error
+	return total, err
+}
@@ -0,0 +1,60 @@
+package post
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Scheduler periodically publishes scheduled posts that are due.
+//
+// Every API process runs one so its in-memory cache notices posts published
+// elsewhere. Publishing itself can be turned off, e.g. when a dedicated
+// `post scheduler` process does it instead.
+type Scheduler struct {
+	service  Service
+	interval time.Duration
+	publish  bool
+}
+
+func NewScheduler(service Service, interval time.Duration, publish bool) *Scheduler {
+	return &Scheduler{service, interval, publish}
+}
+
+// Run blocks until ctx is cancelled.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	since := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			since = s.tick(since)
+		}
+	}
+}
+
+// tick returns the time the next tick should look back to.
+func (s *Scheduler) tick(since time.Time) time.Time {
+	now := time.Now()
+
+	if s.publish {
+		count, err := s.service.PublishDue()
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to publish scheduled posts")
+		} else if count > 0 {
+			log.Info().Int("count", count).Msg("Published scheduled posts")
+		}
+	}
+
+	// Look back one extra interval to tolerate clock drift between replicas
+	if err := s.service.SyncPublished(since.Add(-s.interval)); err != nil {
+		log.Error().Err(err).Msg("Failed to check for published posts")
+		return since
+	}
+	return now
+}
@@ -1,17 +1,48 @@
 package post
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"post/internal/entity"
 	"post/internal/pkg/cache"
+	pkgdb "post/internal/pkg/database"
+	"post/internal/pkg/diff"
+	"post/internal/pkg/response"
+	"post/internal/pkg/slug"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	ErrForbidden       = errors.New("you are not allowed to modify this post")
+	ErrInvalidSchedule = errors.New("publish_at must be in the future")
+	ErrInvalidReaction = errors.New("unknown reaction")
 )
 
 type Service interface {
 	Create(userID uint, input CreatePostInput) (*entity.Post, error)
 	GetAll() ([]entity.Post, error)
-	GetByID(id uint) (*entity.Post, error)
-	GetByUserID(userID uint) ([]entity.Post, error)
+	List(viewer Viewer, query ListQuery) (*PostPage, error)
+	Feed(viewer Viewer, query FeedQuery) (*PostPage, error)
+	Search(viewer Viewer, query SearchQuery) (*SearchPage, error)
+	GetByID(id uint, viewer Viewer) (*entity.Post, error)
+	GetBySlug(slug string, viewer Viewer) (*entity.Post, error)
+	GetByUserID(userID uint, viewer Viewer, query AuthorQuery) (*PostPage, error)
+	Update(id uint, viewer Viewer, input UpdatePostInput) (*entity.Post, error)
+	SetStatus(id uint, viewer Viewer, status entity.PostStatus) (*entity.Post, error)
+	Schedule(id uint, viewer Viewer, publishAt time.Time) (*entity.Post, error)
+	PublishDue() (int, error)
+	SyncPublished(since time.Time) error
+	GetRevisions(id uint, viewer Viewer) ([]entity.PostRevision, error)
+	DiffRevisions(id uint, viewer Viewer, from, to int) (string, error)
+	RestoreRevision(id uint, viewer Viewer, number int) (*entity.Post, error)
+	DeleteOwned(id uint, viewer Viewer) error
 	Delete(id uint) error
+	React(id uint, viewer Viewer, kind entity.ReactionKind) (*ReactionSummary, error)
+	Unreact(id uint, viewer Viewer, kind entity.ReactionKind) (*ReactionSummary, error)
+	AttachReactions(viewer Viewer, posts []entity.Post) error
 }
 
 type service struct {
@@ -23,22 +54,133 @@ func NewService(repo Repository, cache cache.Cache) Service {
 	return &service{repo, cache}
 }
 
+// CreatePostInput creates a draft unless status is "published" or a future
+// publish_at is given, in which case the post is scheduled.
 type CreatePostInput struct {
-	Title   string `json:"title" binding:"required"`
-	Content string `json:"content" binding:"required"`
+	Title         string               `json:"title" binding:"required"`
+	Content       string               `json:"content" binding:"required,max=20000"`
+	ContentFormat entity.ContentFormat `json:"content_format" binding:"omitempty,oneof=plain markdown"`
+	Tags          []string             `json:"tags" binding:"omitempty,max=10,dive,min=1,max=32"`
+	Status        entity.PostStatus    `json:"status" binding:"omitempty,oneof=draft published"`
+	PublishAt     *time.Time           `json:"publish_at"`
+}
+
+type ScheduleInput struct {
+	PublishAt time.Time `json:"publish_at" binding:"required"`
+}
+
+// DiffQuery selects the two revisions to compare.
+type DiffQuery struct {
+	From int `form:"from" binding:"required,min=1"`
+	To   int `form:"to" binding:"required,min=1"`
+}
+
+// slugAttempts bounds how often Create picks a new slug after losing a race
+// for the same one.
+const slugAttempts = 3
+
+// publishBatchSize bounds how many scheduled posts PublishDue claims per query.
+const publishBatchSize = 100
+
+// ListQuery selects a page of posts. Offset pagination is used when page or
+// per_page is given, otherwise the list is paginated by cursor.
+type ListQuery struct {
+	Limit   int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Cursor  string `form:"cursor"`
+	Page    int    `form:"page" binding:"omitempty,min=1"`
+	PerPage int    `form:"per_page" binding:"omitempty,min=1,max=100"`
+	Tag     string `form:"tag"`
+	Author  uint   `form:"author_id"`
+}
+
+func (q ListQuery) filter(viewer Viewer) Filter {
+	return Filter{
+		Tag:         normalizeTag(q.Tag),
+		AuthorID:    q.Author,
+		ViewerID:    viewer.UserID,
+		AllStatuses: viewer.Can(entity.PermPostsReadAny),
+	}
+}
+
+// FeedQuery selects a page of the home feed, which is always paginated by
+// cursor.
+type FeedQuery struct {
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Cursor string `form:"cursor"`
+}
+
+// PostPage is one page of posts. Page is zero for cursor-paginated results.
+type PostPage struct {
+	Posts      []entity.Post
+	Total      int64
+	HasMore    bool
+	NextCursor string
+	Limit      int
+	Page       int
+}
+
+// AuthorQuery selects a page of an author's posts.
+type AuthorQuery struct {
+	Page    int `form:"page" binding:"omitempty,min=1"`
+	PerPage int `form:"per_page" binding:"omitempty,min=1,max=100"`
+}
+
+type SearchQuery struct {
+	Q       string `form:"q" binding:"required"`
+	Page    int    `form:"page" binding:"omitempty,min=1"`
+	PerPage int    `form:"per_page" binding:"omitempty,min=1,max=100"`
+}
+
+// SearchPage is one page of search results, ordered by relevance.
+type SearchPage struct {
+	Results []SearchResult
+	Total   int64
+	HasMore bool
+	Limit   int
+	Page    int
+}
+
+// UpdatePostInput only changes the fields that are present in the request.
+type UpdatePostInput struct {
+	Title         *string               `json:"title" binding:"omitempty,min=1"`
+	Content       *string               `json:"content" binding:"omitempty,min=1,max=20000"`
+	ContentFormat *entity.ContentFormat `json:"content_format" binding:"omitempty,oneof=plain markdown"`
+	Tags          *[]string             `json:"tags" binding:"omitempty,max=10,dive,min=1,max=32"`
 }
 
 func (s *service) Create(userID uint, input CreatePostInput) (*entity.Post, error) {
 	post := &entity.Post{
-		UserID:  userID,
-		Title:   input.Title,
-		Content: input.Content,
+		UserID:        userID,
+		Title:         input.Title,
+		Content:       input.Content,
+		ContentFormat: input.ContentFormat,
+		Tags:          tagsFromNames(input.Tags),
+		Status:        entity.PostStatusDraft,
 	}
-	if err := s.repo.Create(post); err != nil {
-		return nil, err
+	render(post)
+	switch {
+	case input.PublishAt != nil:
+		if !input.PublishAt.After(time.Now()) {
+			return nil, ErrInvalidSchedule
+		}
+		post.Status = entity.PostStatusScheduled
+		post.PublishAt = input.PublishAt
+	case input.Status == entity.PostStatusPublished:
+		publish(post)
 	}
-	// Invalidate Cache
-	s.cache.Delete("all_posts")
+	for attempt := 1; ; attempt++ {
+		if err := s.assignSlug(post); err != nil {
+			return nil, err
+		}
+		err := s.repo.Create(post)
+		if err == nil {
+			break
+		}
+		if pkgdb.ParseError(err) != pkgdb.ErrDuplicateKey || attempt == slugAttempts {
+			return nil, err
+		}
+	}
+	s.invalidate()
 	return post, nil
 }
 
@@ -61,19 +203,579 @@ func (s *service) GetAll() ([]entity.Post, error) {
 	return posts, nil
 }
 
-func (s *service) GetByID(id uint) (*entity.Post, error) {
-	return s.repo.FindByID(id)
+// List returns a page of posts. Pages are cached per viewer scope, reactions
+// are added afterwards so they are always current and the caller's own.
+func (s *service) List(viewer Viewer, query ListQuery) (*PostPage, error) {
+	var page *PostPage
+	var err error
+	if query.Page > 0 || query.PerPage > 0 {
+		page, err = s.listByOffset(viewer, query)
+	} else {
+		page, err = s.listByCursor(viewer, query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.withReactions(viewer, page)
+}
+
+// withReactions returns a copy of a cached page with the viewer's reactions
+// filled in. The cached page itself is shared and left alone.
+func (s *service) withReactions(viewer Viewer, page *PostPage) (*PostPage, error) {
+	withReactions := *page
+	withReactions.Posts = slices.Clone(page.Posts)
+	if err := s.AttachReactions(viewer, withReactions.Posts); err != nil {
+		return nil, err
+	}
+	return &withReactions, nil
+}
+
+func (s *service) listByCursor(viewer Viewer, query ListQuery) (*PostPage, error) {
+	limit := response.PageSize(query.Limit)
+
+	var after *Cursor
+	if query.Cursor != "" {
+		c, err := DecodeCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		after = c
+	}
+
+	filter := query.filter(viewer)
+	key := fmt.Sprintf("posts:%s:cursor:%s:%d:%s", viewer.scope(), query.Cursor, limit, filter.key())
+	return s.cachedPage(key, func() (*PostPage, error) {
+		// Fetch one extra row to know whether another page exists
+		posts, err := s.repo.FindPage(filter, after, 0, limit+1)
+		if err != nil {
+			return nil, err
+		}
+		total, err := s.repo.Count(filter)
+		if err != nil {
+			return nil, err
+		}
+
+		page := &PostPage{Posts: posts, Total: total, Limit: limit}
+		if len(posts) > limit {
+			page.Posts = posts[:limit]
+			page.HasMore = true
+			last := page.Posts[limit-1]
+			page.NextCursor = Cursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+		}
+		return page, nil
+	})
+}
+
+// Feed returns the published posts of the authors the viewer follows, newest
+// first. It is neither cached nor counted, following many authors would make
+// both costly.
+func (s *service) Feed(viewer Viewer, query FeedQuery) (*PostPage, error) {
+	limit := response.PageSize(query.Limit)
+
+	var after *Cursor
+	if query.Cursor != "" {
+		c, err := DecodeCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		after = c
+	}
+
+	posts, err := s.repo.FindPage(Filter{FollowedBy: viewer.UserID}, after, 0, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &PostPage{Posts: posts, Limit: limit}
+	if len(posts) > limit {
+		page.Posts = posts[:limit]
+		page.HasMore = true
+		last := page.Posts[limit-1]
+		page.NextCursor = Cursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+	}
+
+	if err := s.AttachReactions(viewer, page.Posts); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+func (s *service) listByOffset(viewer Viewer, query ListQuery) (*PostPage, error) {
+	pageNum, perPage, offset := response.PageBounds(query.Page, query.PerPage)
+
+	filter := query.filter(viewer)
+	key := fmt.Sprintf("posts:%s:page:%d:%d:%s", viewer.scope(), pageNum, perPage, filter.key())
+	return s.cachedPage(key, func() (*PostPage, error) {
+		posts, err := s.repo.FindPage(filter, nil, offset, perPage)
+		if err != nil {
+			return nil, err
+		}
+		total, err := s.repo.Count(filter)
+		if err != nil {
+			return nil, err
+		}
+
+		return &PostPage{
+			Posts:   posts,
+			Total:   total,
+			HasMore: int64(offset+len(posts)) < total,
+			Limit:   perPage,
+			Page:    pageNum,
+		}, nil
+	})
+}
+
+func (s *service) Search(viewer Viewer, query SearchQuery) (*SearchPage, error) {
+	pageNum, perPage, offset := response.PageBounds(query.Page, query.PerPage)
+
+	results, err := s.repo.Search(query.Q, offset, perPage)
+	if err != nil {
+		return nil, err
+	}
+	posts := make([]*entity.Post, len(results))
+	for i := range results {
+		posts[i] = &results[i].Post
+	}
+	if err := s.attachReactions(viewer, posts...); err != nil {
+		return nil, err
+	}
+	total, err := s.repo.CountSearch(query.Q)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SearchPage{
+		Results: results,
+		Total:   total,
+		HasMore: int64(offset+len(results)) < total,
+		Limit:   perPage,
+		Page:    pageNum,
+	}, nil
+}
+
+func (s *service) cachedPage(key string, load func() (*PostPage, error)) (*PostPage, error) {
+	if val, ok := s.cache.Get(key); ok {
+		log.Printf("Hit Cache: %s", key)
+		return val.(*PostPage), nil
+	}
+
+	page, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	s.cache.Set(key, page)
+	log.Printf("Miss Cache: %s (Set)", key)
+	return page, nil
+}
+
+// GetByID returns the post if the viewer is allowed to see it. Posts the
+// viewer cannot see are reported as not found so their existence is not leaked.
+func (s *service) GetByID(id uint, viewer Viewer) (*entity.Post, error) {
+	post, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, pkgdb.ParseError(err)
+	}
+	if !viewer.CanSee(post) {
+		return nil, pkgdb.ErrRecordNotFound
+	}
+	if err := s.attachReactions(viewer, post); err != nil {
+		return nil, err
+	}
+	return post, nil
 }
 
-func (s *service) GetByUserID(userID uint) ([]entity.Post, error) {
-	return s.repo.FindByUserID(userID)
+// GetBySlug returns the post currently or previously reachable under slug.
+// Callers can compare the slug of the returned post to detect an old one.
+func (s *service) GetBySlug(slug string, viewer Viewer) (*entity.Post, error) {
+	post, err := s.repo.FindBySlug(slug)
+	if err == nil {
+		if !viewer.CanSee(post) {
+			return nil, pkgdb.ErrRecordNotFound
+		}
+		if err := s.attachReactions(viewer, post); err != nil {
+			return nil, err
+		}
+		return post, nil
+	}
+	if err = pkgdb.ParseError(err); err != pkgdb.ErrRecordNotFound {
+		return nil, err
+	}
+
+	id, err := s.repo.FindPostIDByOldSlug(slug)
+	if err != nil {
+		return nil, pkgdb.ParseError(err)
+	}
+	return s.GetByID(id, viewer)
+}
+
+// GetByUserID returns a page of the author's published posts, newest first.
+// Drafts are left out even for the author and admins, the page shows what
+// readers see. It shares the public cache with List.
+func (s *service) GetByUserID(userID uint, viewer Viewer, query AuthorQuery) (*PostPage, error) {
+	page, err := s.listByOffset(Viewer{}, ListQuery{Page: query.Page, PerPage: query.PerPage, Author: userID})
+	if err != nil {
+		return nil, err
+	}
+	return s.withReactions(viewer, page)
+}
+
+func (s *service) Update(id uint, viewer Viewer, input UpdatePostInput) (*entity.Post, error) {
+	post, err := s.findOwned(id, viewer)
+	if err != nil {
+		return nil, err
+	}
+
+	title, content, format := post.Title, post.Content, post.ContentFormat
+	if input.Title != nil {
+		post.Title = *input.Title
+	}
+	if input.Content != nil {
+		post.Content = *input.Content
+	}
+	if input.ContentFormat != nil {
+		post.ContentFormat = *input.ContentFormat
+	}
+	if input.Tags != nil {
+		post.Tags = tagsFromNames(*input.Tags)
+	}
+	if post.Content != content || post.ContentFormat != format {
+		render(post)
+	}
+
+	// Only title and content are versioned
+	if post.Title != title || post.Content != content {
+		if post.Title != title {
+			if err := s.assignSlug(post); err != nil {
+				return nil, err
+			}
+		}
+		err = s.repo.UpdateWithRevision(post, viewer.UserID)
+	} else {
+		err = s.repo.Update(post)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.invalidate()
+	return post, nil
+}
+
+// SetStatus moves a post through its lifecycle. PublishedAt is set the first
+// time a post is published and kept when it is unpublished or archived.
+func (s *service) SetStatus(id uint, viewer Viewer, status entity.PostStatus) (*entity.Post, error) {
+	post, err := s.findOwned(id, viewer)
+	if err != nil {
+		return nil, err
+	}
+	if post.Status == status {
+		return post, nil
+	}
+
+	if status == entity.PostStatusPublished {
+		publish(post)
+	} else {
+		post.Status = status
+	}
+	if err := s.repo.Update(post); err != nil {
+		return nil, err
+	}
+	s.invalidate()
+	return post, nil
+}
+
+func (s *service) Schedule(id uint, viewer Viewer, publishAt time.Time) (*entity.Post, error) {
+	if !publishAt.After(time.Now()) {
+		return nil, ErrInvalidSchedule
+	}
+
+	post, err := s.findOwned(id, viewer)
+	if err != nil {
+		return nil, err
+	}
+
+	post.Status = entity.PostStatusScheduled
+	post.PublishAt = &publishAt
+	if err := s.repo.Update(post); err != nil {
+		return nil, err
+	}
+	s.invalidate()
+	return post, nil
+}
+
+// PublishDue publishes every scheduled post that is due and returns how many
+// went live.
+func (s *service) PublishDue() (int, error) {
+	total := 0
+	for {
+		ids, err := s.repo.PublishDue(time.Now(), publishBatchSize)
+		if err != nil {
+			return total, err
+		}
+		total += len(ids)
+		if len(ids) < publishBatchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		s.invalidate()
+	}
+	return total, nil
+}
+
+// SyncPublished drops cached lists when scheduled posts were published since
+// the given time, possibly by another process whose cache is not ours.
+func (s *service) SyncPublished(since time.Time) error {
+	count, err := s.repo.CountPublishedSince(since)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		s.invalidate()
+	}
+	return nil
+}
+
+// GetRevisions lists a post's history. Earlier revisions may hold content
+// that was never published, so only the author and admins can read them.
+func (s *service) GetRevisions(id uint, viewer Viewer) ([]entity.PostRevision, error) {
+	if _, err := s.findOwned(id, viewer); err != nil {
+		return nil, err
+	}
+	return s.repo.FindRevisions(id)
+}
+
+func (s *service) DiffRevisions(id uint, viewer Viewer, from, to int) (string, error) {
+	if _, err := s.findOwned(id, viewer); err != nil {
+		return "", err
+	}
+
+	a, err := s.repo.FindRevision(id, from)
+	if err != nil {
+		return "", pkgdb.ParseError(err)
+	}
+	b, err := s.repo.FindRevision(id, to)
+	if err != nil {
+		return "", pkgdb.ParseError(err)
+	}
+
+	return diff.Unified(
+		fmt.Sprintf("revision %d", a.Number),
+		fmt.Sprintf("revision %d", b.Number),
+		revisionText(a),
+		revisionText(b),
+		3,
+	)
+}
+
+// RestoreRevision brings back the title and content of an earlier revision.
+// The restore is itself recorded as a new revision.
+func (s *service) RestoreRevision(id uint, viewer Viewer, number int) (*entity.Post, error) {
+	post, err := s.findOwned(id, viewer)
+	if err != nil {
+		return nil, err
+	}
+
+	revision, err := s.repo.FindRevision(id, number)
+	if err != nil {
+		return nil, pkgdb.ParseError(err)
+	}
+
+	if post.Title != revision.Title {
+		post.Title = revision.Title
+		if err := s.assignSlug(post); err != nil {
+			return nil, err
+		}
+	}
+	post.Content = revision.Content
+	render(post)
+	if err := s.repo.UpdateWithRevision(post, viewer.UserID); err != nil {
+		return nil, err
+	}
+	s.invalidate()
+	return post, nil
+}
+
+// revisionText is what revisions are diffed on: the title, a blank line and
+// the content.
+func revisionText(revision *entity.PostRevision) string {
+	return revision.Title + "\n\n" + revision.Content
+}
+
+func (s *service) DeleteOwned(id uint, viewer Viewer) error {
+	post, err := s.repo.FindByID(id)
+	if err != nil {
+		return pkgdb.ParseError(err)
+	}
+	if !viewer.CanDelete(post) {
+		return ErrForbidden
+	}
+	return s.Delete(id)
 }
 
 func (s *service) Delete(id uint) error {
 	if err := s.repo.Delete(id); err != nil {
 		return err
 	}
-	// Invalidate Cache
-	s.cache.Delete("all_posts")
+	s.invalidate()
 	return nil
 }
+
+// React adds the viewer's reaction to a post they can see. Reacting twice
+// with the same kind has no further effect.
+func (s *service) React(id uint, viewer Viewer, kind entity.ReactionKind) (*ReactionSummary, error) {
+	if err := s.checkReaction(id, viewer, kind); err != nil {
+		return nil, err
+	}
+	reaction := &entity.PostReaction{PostID: id, UserID: viewer.UserID, Kind: kind}
+	if err := s.repo.AddReaction(reaction); err != nil {
+		return nil, err
+	}
+	return s.reactionsOf(id, viewer)
+}
+
+// Unreact removes the viewer's reaction, if they had left it.
+func (s *service) Unreact(id uint, viewer Viewer, kind entity.ReactionKind) (*ReactionSummary, error) {
+	if err := s.checkReaction(id, viewer, kind); err != nil {
+		return nil, err
+	}
+	if err := s.repo.RemoveReaction(id, viewer.UserID, kind); err != nil {
+		return nil, err
+	}
+	return s.reactionsOf(id, viewer)
+}
+
+func (s *service) checkReaction(id uint, viewer Viewer, kind entity.ReactionKind) error {
+	if !kind.Valid() {
+		return ErrInvalidReaction
+	}
+	post, err := s.repo.FindByID(id)
+	if err != nil {
+		return pkgdb.ParseError(err)
+	}
+	if !viewer.CanSee(post) {
+		return pkgdb.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (s *service) reactionsOf(id uint, viewer Viewer) (*ReactionSummary, error) {
+	summaries, err := s.repo.FindReactions([]uint{id}, viewer.UserID)
+	if err != nil {
+		return nil, err
+	}
+	summary := normalizeSummary(summaries[id])
+	return &summary, nil
+}
+
+// AttachReactions fills in the reaction counts and the viewer's own
+// reactions on posts, including posts loaded by other packages.
+func (s *service) AttachReactions(viewer Viewer, posts []entity.Post) error {
+	ptrs := make([]*entity.Post, len(posts))
+	for i := range posts {
+		ptrs[i] = &posts[i]
+	}
+	return s.attachReactions(viewer, ptrs...)
+}
+
+// attachReactions fills in reaction counts and the viewer's own reactions
+// for all posts with a single query.
+func (s *service) attachReactions(viewer Viewer, posts ...*entity.Post) error {
+	if len(posts) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(posts))
+	for i, post := range posts {
+		ids[i] = post.ID
+	}
+
+	summaries, err := s.repo.FindReactions(ids, viewer.UserID)
+	if err != nil {
+		return err
+	}
+	for _, post := range posts {
+		summary := normalizeSummary(summaries[post.ID])
+		post.Reactions = summary.Counts
+		post.MyReactions = summary.Mine
+	}
+	return nil
+}
+
+// normalizeSummary replaces nil fields so they encode as {} and [].
+func normalizeSummary(summary ReactionSummary) ReactionSummary {
+	if summary.Counts == nil {
+		summary.Counts = map[entity.ReactionKind]int64{}
+	}
+	if summary.Mine == nil {
+		summary.Mine = []entity.ReactionKind{}
+	}
+	return summary
+}
+
+// tagsFromNames turns user supplied tag names into unsaved tags,
+// normalizing case and dropping duplicates.
+func tagsFromNames(names []string) []entity.Tag {
+	tags := make([]entity.Tag, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = normalizeTag(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		tags = append(tags, entity.Tag{Name: name})
+	}
+	return tags
+}
+
+// assignSlug derives the post slug from its title, appending -2, -3, ... when
+// the plain slug is already taken by another post.
+func (s *service) assignSlug(post *entity.Post) error {
+	base := slug.Make(post.Title)
+	taken, err := s.repo.FindTakenSlugs(base, post.ID)
+	if err != nil {
+		return err
+	}
+
+	used := make(map[string]bool, len(taken))
+	for _, t := range taken {
+		used[t] = true
+	}
+	candidate := base
+	for n := 2; used[candidate]; n++ {
+		candidate = fmt.Sprintf("%s-%d", base, n)
+	}
+	post.Slug = candidate
+	return nil
+}
+
+func normalizeTag(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// invalidate drops the cached full list and every cached page after a write.
+func (s *service) invalidate() {
+	s.cache.Delete("all_posts")
+	s.cache.DeletePrefix("posts:")
+}
+
+// findOwned loads a post and checks that the caller may edit it.
+func (s *service) findOwned(id uint, viewer Viewer) (*entity.Post, error) {
+	post, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, pkgdb.ParseError(err)
+	}
+	if !viewer.CanManage(post) {
+		return nil, ErrForbidden
+	}
+	return post, nil
+}
+
+func publish(post *entity.Post) {
+	post.Status = entity.PostStatusPublished
+	if post.PublishedAt == nil {
+		now := time.Now()
+		post.PublishedAt = &now
+	}
+}
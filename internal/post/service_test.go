@@ -2,14 +2,18 @@ package post_test
 
 import (
 	"errors"
+	"strings"
 	"testing"
 	"time"
+	"unicode/utf8"
 
 	"post/internal/entity"
+	pkgdb "post/internal/pkg/database"
 	"post/internal/post"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 )
 
 // MockRepository is a mock of post.Repository
@@ -27,6 +31,31 @@ func (m *MockRepository) FindAll() ([]entity.Post, error) {
 	return args.Get(0).([]entity.Post), args.Error(1)
 }
 
+func (m *MockRepository) FindPage(filter post.Filter, after *post.Cursor, offset, limit int) ([]entity.Post, error) {
+	args := m.Called(filter, after, offset, limit)
+	return args.Get(0).([]entity.Post), args.Error(1)
+}
+
+func (m *MockRepository) Count(filter post.Filter) (int64, error) {
+	args := m.Called(filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRepository) Search(query string, offset, limit int) ([]post.SearchResult, error) {
+	args := m.Called(query, offset, limit)
+	return args.Get(0).([]post.SearchResult), args.Error(1)
+}
+
+func (m *MockRepository) CountSearch(query string) (int64, error) {
+	args := m.Called(query)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRepository) FindPublishedLinks(offset, limit int) ([]post.Link, error) {
+	args := m.Called(offset, limit)
+	return args.Get(0).([]post.Link), args.Error(1)
+}
+
 func (m *MockRepository) FindByID(id uint) (*entity.Post, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
@@ -35,9 +64,22 @@ func (m *MockRepository) FindByID(id uint) (*entity.Post, error) {
 	return args.Get(0).(*entity.Post), args.Error(1)
 }
 
-func (m *MockRepository) FindByUserID(userID uint) ([]entity.Post, error) {
-	args := m.Called(userID)
-	return args.Get(0).([]entity.Post), args.Error(1)
+func (m *MockRepository) FindBySlug(slug string) (*entity.Post, error) {
+	args := m.Called(slug)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Post), args.Error(1)
+}
+
+func (m *MockRepository) FindPostIDByOldSlug(slug string) (uint, error) {
+	args := m.Called(slug)
+	return args.Get(0).(uint), args.Error(1)
+}
+
+func (m *MockRepository) FindTakenSlugs(base string, exceptPostID uint) ([]string, error) {
+	args := m.Called(base, exceptPostID)
+	return args.Get(0).([]string), args.Error(1)
 }
 
 func (m *MockRepository) Update(p *entity.Post) error {
@@ -50,6 +92,52 @@ func (m *MockRepository) Delete(id uint) error {
 	return args.Error(0)
 }
 
+func (m *MockRepository) PublishDue(now time.Time, limit int) ([]uint, error) {
+	args := m.Called(now, limit)
+	return args.Get(0).([]uint), args.Error(1)
+}
+
+func (m *MockRepository) CountPublishedSince(since time.Time) (int64, error) {
+	args := m.Called(since)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRepository) UpdateWithRevision(p *entity.Post, editorID uint) error {
+	args := m.Called(p, editorID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) FindRevisions(postID uint) ([]entity.PostRevision, error) {
+	args := m.Called(postID)
+	return args.Get(0).([]entity.PostRevision), args.Error(1)
+}
+
+func (m *MockRepository) FindRevision(postID uint, number int) (*entity.PostRevision, error) {
+	args := m.Called(postID, number)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.PostRevision), args.Error(1)
+}
+
+func (m *MockRepository) AddReaction(r *entity.PostReaction) error {
+	args := m.Called(r)
+	return args.Error(0)
+}
+
+func (m *MockRepository) RemoveReaction(postID, userID uint, kind entity.ReactionKind) error {
+	args := m.Called(postID, userID, kind)
+	return args.Error(0)
+}
+
+func (m *MockRepository) FindReactions(postIDs []uint, userID uint) (map[uint]post.ReactionSummary, error) {
+	args := m.Called(postIDs, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[uint]post.ReactionSummary), args.Error(1)
+}
+
 // MockCache is a mock of cache.Cache
 type MockCache struct {
 	mock.Mock
@@ -68,6 +156,10 @@ func (m *MockCache) Delete(key string) {
 	m.Called(key)
 }
 
+func (m *MockCache) DeletePrefix(prefix string) {
+	m.Called(prefix)
+}
+
 func (m *MockCache) Purge() {
 	m.Called()
 }
@@ -80,12 +172,15 @@ func TestCreate(t *testing.T) {
 		userID := uint(1)
 		input := post.CreatePostInput{Title: "Test Post", Content: "Content"}
 
+		mockRepo.On("FindTakenSlugs", mock.Anything, uint(0)).Return([]string{}, nil)
 		mockRepo.On("Create", mock.MatchedBy(func(p *entity.Post) bool {
-			return p.UserID == userID && p.Title == input.Title && p.Content == input.Content
+			return p.UserID == userID && p.Title == input.Title && p.Content == input.Content &&
+				p.Status == entity.PostStatusDraft && p.PublishedAt == nil
 		})).Return(nil)
 
 		// Expect cache invalidation
 		mockCache.On("Delete", "all_posts").Return()
+		mockCache.On("DeletePrefix", "posts:").Return()
 
 		result, err := service.Create(userID, input)
 
@@ -96,6 +191,73 @@ func TestCreate(t *testing.T) {
 		mockCache.AssertExpectations(t)
 	})
 
+	t.Run("Tags", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockCache := new(MockCache)
+		service := post.NewService(mockRepo, mockCache)
+		input := post.CreatePostInput{Title: "Test Post", Content: "Content", Tags: []string{"Go", " go", "web", ""}}
+
+		mockRepo.On("FindTakenSlugs", mock.Anything, uint(0)).Return([]string{}, nil)
+		mockRepo.On("Create", mock.MatchedBy(func(p *entity.Post) bool {
+			return len(p.Tags) == 2 && p.Tags[0].Name == "go" && p.Tags[1].Name == "web"
+		})).Return(nil)
+		mockCache.On("Delete", "all_posts").Return()
+		mockCache.On("DeletePrefix", "posts:").Return()
+
+		_, err := service.Create(1, input)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Plain", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockCache := new(MockCache)
+		service := post.NewService(mockRepo, mockCache)
+		input := post.CreatePostInput{Title: "Test Post", Content: "Hello <b>there</b>\nfriend\n\nSecond"}
+
+		mockRepo.On("FindTakenSlugs", mock.Anything, uint(0)).Return([]string{}, nil)
+		mockRepo.On("Create", mock.AnythingOfType("*entity.Post")).Return(nil)
+		mockCache.On("Delete", "all_posts").Return()
+		mockCache.On("DeletePrefix", "posts:").Return()
+
+		result, err := service.Create(1, input)
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.ContentFormatPlain, result.ContentFormat)
+		assert.Equal(t, "<p>Hello &lt;b&gt;there&lt;/b&gt;<br/>\nfriend</p>\n<p>Second</p>", result.ContentHTML)
+		assert.Equal(t, "Hello <b>there</b> friend Second", result.Excerpt)
+		assert.Equal(t, 1, result.ReadingTime)
+	})
+
+	t.Run("Markdown", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockCache := new(MockCache)
+		service := post.NewService(mockRepo, mockCache)
+		content := "# Title\n\nSome **bold** [link](javascript:alert(1)) text.\n\n<script>alert(1)</script>\n\n" +
+			"<img src=x onerror=alert(1)>\n\n" + strings.Repeat("word ", 450)
+		input := post.CreatePostInput{Title: "Test Post", Content: content, ContentFormat: entity.ContentFormatMarkdown}
+
+		mockRepo.On("FindTakenSlugs", mock.Anything, uint(0)).Return([]string{}, nil)
+		mockRepo.On("Create", mock.AnythingOfType("*entity.Post")).Return(nil)
+		mockCache.On("Delete", "all_posts").Return()
+		mockCache.On("DeletePrefix", "posts:").Return()
+
+		result, err := service.Create(1, input)
+
+		assert.NoError(t, err)
+		assert.Equal(t, content, result.Content)
+		assert.Contains(t, result.ContentHTML, "<h1>Title</h1>")
+		assert.Contains(t, result.ContentHTML, `<strong>bold</strong> <a rel="nofollow">link</a>`)
+		assert.Contains(t, result.ContentHTML, `<img src="x"/>`)
+		assert.NotContains(t, result.ContentHTML, "script")
+		assert.NotContains(t, result.ContentHTML, "onerror")
+		assert.True(t, strings.HasPrefix(result.Excerpt, "Title Some bold link text. word word"))
+		assert.True(t, strings.HasSuffix(result.Excerpt, "word…"))
+		assert.LessOrEqual(t, utf8.RuneCountInString(result.Excerpt), 161)
+		assert.Equal(t, 3, result.ReadingTime)
+	})
+
 	t.Run("Failure", func(t *testing.T) {
 		mockRepo := new(MockRepository)
 		mockCache := new(MockCache)
@@ -103,6 +265,7 @@ func TestCreate(t *testing.T) {
 		userID := uint(1)
 		input := post.CreatePostInput{Title: "Test Post", Content: "Content"}
 
+		mockRepo.On("FindTakenSlugs", mock.Anything, uint(0)).Return([]string{}, nil)
 		mockRepo.On("Create", mock.AnythingOfType("*entity.Post")).Return(errors.New("db error"))
 
 		result, err := service.Create(userID, input)
@@ -162,17 +325,208 @@ func TestGetAll(t *testing.T) {
 	})
 }
 
+func TestList(t *testing.T) {
+	now := time.Now()
+
+	t.Run("Cache Hit", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockCache := new(MockCache)
+		service := post.NewService(mockRepo, mockCache)
+		cached := &post.PostPage{Posts: []entity.Post{{ID: 1}}, Total: 1, Limit: 20}
+
+		mockCache.On("Get", "posts:public:cursor::20:").Return(cached, true)
+		mockRepo.On("FindReactions", []uint{1}, uint(0)).Return(map[uint]post.ReactionSummary{
+			1: {Counts: map[entity.ReactionKind]int64{entity.ReactionLike: 2}},
+		}, nil)
+
+		result, err := service.List(post.Viewer{}, post.ListQuery{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, cached.Total, result.Total)
+		assert.Equal(t, map[entity.ReactionKind]int64{entity.ReactionLike: 2}, result.Posts[0].Reactions)
+		assert.Empty(t, result.Posts[0].MyReactions)
+		assert.Nil(t, cached.Posts[0].Reactions, "cached page must not be modified")
+		mockRepo.AssertNotCalled(t, "FindPage", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Cursor", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockCache := new(MockCache)
+		service := post.NewService(mockRepo, mockCache)
+		posts := []entity.Post{
+			{ID: 3, CreatedAt: now},
+			{ID: 2, CreatedAt: now.Add(-time.Minute)},
+			{ID: 1, CreatedAt: now.Add(-2 * time.Minute)},
+		}
+
+		mockCache.On("Get", "posts:public:cursor::2:").Return(nil, false)
+		mockRepo.On("FindPage", post.Filter{}, (*post.Cursor)(nil), 0, 3).Return(posts, nil)
+		mockRepo.On("Count", post.Filter{}).Return(int64(3), nil)
+		mockRepo.On("FindReactions", []uint{3, 2}, uint(0)).Return(map[uint]post.ReactionSummary{}, nil)
+		mockCache.On("Set", "posts:public:cursor::2:", mock.AnythingOfType("*post.PostPage")).Return()
+
+		result, err := service.List(post.Viewer{}, post.ListQuery{Limit: 2})
+
+		assert.NoError(t, err)
+		assert.Len(t, result.Posts, 2)
+		assert.True(t, result.HasMore)
+		assert.Equal(t, int64(3), result.Total)
+
+		cursor, err := post.DecodeCursor(result.NextCursor)
+		assert.NoError(t, err)
+		assert.Equal(t, uint(2), cursor.ID)
+		assert.True(t, posts[1].CreatedAt.Equal(cursor.CreatedAt))
+		mockRepo.AssertExpectations(t)
+		mockCache.AssertExpectations(t)
+	})
+
+	t.Run("Offset", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockCache := new(MockCache)
+		service := post.NewService(mockRepo, mockCache)
+		posts := []entity.Post{{ID: 1}}
+
+		filter := post.Filter{Tag: "go", ViewerID: 5}
+
+		mockCache.On("Get", "posts:user5:page:2:10:go").Return(nil, false)
+		mockRepo.On("FindPage", filter, (*post.Cursor)(nil), 10, 10).Return(posts, nil)
+		mockRepo.On("Count", filter).Return(int64(11), nil)
+		mockRepo.On("FindReactions", mock.Anything, mock.Anything).Return(map[uint]post.ReactionSummary{}, nil)
+		mockCache.On("Set", "posts:user5:page:2:10:go", mock.AnythingOfType("*post.PostPage")).Return()
+
+		result, err := service.List(post.Viewer{UserID: 5, Role: entity.RoleUser}, post.ListQuery{Page: 2, PerPage: 10, Tag: " Go "})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, result.Page)
+		assert.False(t, result.HasMore)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid Cursor", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockCache := new(MockCache)
+		service := post.NewService(mockRepo, mockCache)
+
+		result, err := service.List(post.Viewer{}, post.ListQuery{Cursor: "not-a-cursor"})
+
+		assert.ErrorIs(t, err, post.ErrInvalidCursor)
+		assert.Nil(t, result)
+	})
+}
+
+func TestGetByUserID(t *testing.T) {
+	t.Run("Published Only", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockCache := new(MockCache)
+		service := post.NewService(mockRepo, mockCache)
+		author := post.Viewer{UserID: 3, Role: entity.RoleUser}
+		filter := post.Filter{AuthorID: 3}
+
+		mockCache.On("Get", "posts:public:page:1:20::author3").Return(nil, false)
+		mockRepo.On("FindPage", filter, (*post.Cursor)(nil), 0, 20).Return([]entity.Post{{ID: 7, UserID: 3}}, nil)
+		mockRepo.On("Count", filter).Return(int64(1), nil)
+		mockCache.On("Set", "posts:public:page:1:20::author3", mock.AnythingOfType("*post.PostPage")).Return()
+		mockRepo.On("FindReactions", []uint{7}, uint(3)).Return(map[uint]post.ReactionSummary{}, nil)
+
+		result, err := service.GetByUserID(3, author, post.AuthorQuery{})
+
+		assert.NoError(t, err)
+		assert.Len(t, result.Posts, 1)
+		assert.Equal(t, 1, result.Page)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestFeed(t *testing.T) {
+	now := time.Now()
+	viewer := post.Viewer{UserID: 5, Role: entity.RoleUser}
+
+	t.Run("Followed Authors", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockCache := new(MockCache)
+		service := post.NewService(mockRepo, mockCache)
+		after := post.Cursor{CreatedAt: now, ID: 9}
+		posts := []entity.Post{
+			{ID: 8, UserID: 2, CreatedAt: now.Add(-time.Minute)},
+			{ID: 7, UserID: 3, CreatedAt: now.Add(-2 * time.Minute)},
+		}
+
+		mockRepo.On("FindPage", post.Filter{FollowedBy: 5}, mock.MatchedBy(func(c *post.Cursor) bool {
+			return c.ID == 9 && c.CreatedAt.Equal(now)
+		}), 0, 2).Return(posts, nil)
+		mockRepo.On("FindReactions", []uint{8}, uint(5)).Return(map[uint]post.ReactionSummary{}, nil)
+
+		result, err := service.Feed(viewer, post.FeedQuery{Limit: 1, Cursor: after.Encode()})
+
+		assert.NoError(t, err)
+		assert.Len(t, result.Posts, 1)
+		assert.True(t, result.HasMore)
+		cursor, err := post.DecodeCursor(result.NextCursor)
+		assert.NoError(t, err)
+		assert.Equal(t, uint(8), cursor.ID)
+		mockRepo.AssertNotCalled(t, "Count", mock.Anything)
+		mockCache.AssertNotCalled(t, "Get", mock.Anything)
+	})
+
+	t.Run("Invalid Cursor", func(t *testing.T) {
+		service := post.NewService(new(MockRepository), new(MockCache))
+
+		_, err := service.Feed(viewer, post.FeedQuery{Cursor: "not-a-cursor"})
+
+		assert.ErrorIs(t, err, post.ErrInvalidCursor)
+	})
+}
+
+func TestSearch(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockCache := new(MockCache)
+		service := post.NewService(mockRepo, mockCache)
+		results := []post.SearchResult{
+			{Post: entity.Post{ID: 1, Title: "Go generics"}, Rank: 0.8, Snippet: "<mark>Go</mark> generics"},
+		}
+
+		mockRepo.On("Search", "go", 20, 20).Return(results, nil)
+		mockRepo.On("CountSearch", "go").Return(int64(21), nil)
+		mockRepo.On("FindReactions", mock.Anything, mock.Anything).Return(map[uint]post.ReactionSummary{}, nil)
+
+		result, err := service.Search(post.Viewer{}, post.SearchQuery{Q: "go", Page: 2})
+
+		assert.NoError(t, err)
+		assert.Equal(t, results, result.Results)
+		assert.Equal(t, 2, result.Page)
+		assert.False(t, result.HasMore)
+		mockRepo.AssertExpectations(t)
+		mockCache.AssertNotCalled(t, "Get", mock.Anything)
+	})
+
+	t.Run("Failure", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockCache := new(MockCache)
+		service := post.NewService(mockRepo, mockCache)
+
+		mockRepo.On("Search", "go", 0, 20).Return([]post.SearchResult(nil), errors.New("db error"))
+
+		result, err := service.Search(post.Viewer{}, post.SearchQuery{Q: "go"})
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		mockRepo.AssertNotCalled(t, "CountSearch", "go")
+	})
+}
+
 func TestGetByID(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		mockRepo := new(MockRepository)
 		mockCache := new(MockCache)
 		service := post.NewService(mockRepo, mockCache)
 		postID := uint(1)
-		expectedPost := &entity.Post{ID: postID, Title: "Test Post", CreatedAt: time.Now()}
+		expectedPost := &entity.Post{ID: postID, Title: "Test Post", Status: entity.PostStatusPublished, CreatedAt: time.Now()}
 
 		mockRepo.On("FindByID", postID).Return(expectedPost, nil)
+		mockRepo.On("FindReactions", mock.Anything, mock.Anything).Return(map[uint]post.ReactionSummary{}, nil)
 
-		result, err := service.GetByID(postID)
+		result, err := service.GetByID(postID, post.Viewer{})
 
 		assert.NoError(t, err)
 		assert.Equal(t, expectedPost, result)
@@ -187,10 +541,571 @@ func TestGetByID(t *testing.T) {
 
 		mockRepo.On("FindByID", postID).Return(nil, errors.New("post not found"))
 
-		result, err := service.GetByID(postID)
+		result, err := service.GetByID(postID, post.Viewer{})
 
 		assert.Error(t, err)
 		assert.Nil(t, result)
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("Draft", func(t *testing.T) {
+		draft := &entity.Post{ID: 3, UserID: 1, Status: entity.PostStatusDraft}
+
+		for name, tc := range map[string]struct {
+			viewer  post.Viewer
+			visible bool
+		}{
+			"Anonymous": {post.Viewer{}, false},
+			"Other":     {post.Viewer{UserID: 2, Role: entity.RoleUser}, false},
+			"Author":    {post.Viewer{UserID: 1, Role: entity.RoleUser}, true},
+			"Admin":     {post.Viewer{UserID: 9, Role: entity.RoleAdmin}, true},
+			"Editor":    {post.Viewer{UserID: 8, Role: entity.RoleEditor}, true},
+		} {
+			t.Run(name, func(t *testing.T) {
+				mockRepo := new(MockRepository)
+				mockCache := new(MockCache)
+				service := post.NewService(mockRepo, mockCache)
+
+				mockRepo.On("FindByID", uint(3)).Return(draft, nil)
+				mockRepo.On("FindReactions", mock.Anything, mock.Anything).Return(map[uint]post.ReactionSummary{}, nil)
+
+				result, err := service.GetByID(3, tc.viewer)
+
+				if tc.visible {
+					assert.NoError(t, err)
+					assert.Equal(t, draft, result)
+				} else {
+					assert.ErrorIs(t, err, pkgdb.ErrRecordNotFound)
+					assert.Nil(t, result)
+				}
+			})
+		}
+	})
+}
+
+func TestUpdate(t *testing.T) {
+	title := "Updated Title"
+
+	t.Run("Author", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockCache := new(MockCache)
+		service := post.NewService(mockRepo, mockCache)
+		existing := &entity.Post{ID: 1, UserID: 1, Title: "Old", Content: "Content"}
+
+		mockRepo.On("FindByID", uint(1)).Return(existing, nil)
+		mockRepo.On("FindTakenSlugs", "updated-title", uint(1)).Return([]string{}, nil)
+		mockRepo.On("UpdateWithRevision", mock.MatchedBy(func(p *entity.Post) bool {
+			return p.Title == title && p.Content == "Content"
+		}), uint(1)).Return(nil)
+		mockCache.On("Delete", "all_posts").Return()
+		mockCache.On("DeletePrefix", "posts:").Return()
+
+		result, err := service.Update(1, post.Viewer{UserID: 1, Role: entity.RoleUser}, post.UpdatePostInput{Title: &title})
+
+		assert.NoError(t, err)
+		assert.Equal(t, title, result.Title)
+		mockRepo.AssertExpectations(t)
+		mockCache.AssertExpectations(t)
+	})
+
+	t.Run("Admin", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockCache := new(MockCache)
+		service := post.NewService(mockRepo, mockCache)
+		existing := &entity.Post{ID: 1, UserID: 1, Title: "Old", Content: "Content"}
+
+		mockRepo.On("FindByID", uint(1)).Return(existing, nil)
+		mockRepo.On("FindTakenSlugs", "updated-title", uint(1)).Return([]string{}, nil)
+		mockRepo.On("UpdateWithRevision", mock.AnythingOfType("*entity.Post"), uint(99)).Return(nil)
+		mockCache.On("Delete", "all_posts").Return()
+		mockCache.On("DeletePrefix", "posts:").Return()
+
+		_, err := service.Update(1, post.Viewer{UserID: 99, Role: entity.RoleAdmin}, post.UpdatePostInput{Title: &title})
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Tags Only", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockCache := new(MockCache)
+		service := post.NewService(mockRepo, mockCache)
+		existing := &entity.Post{ID: 1, UserID: 1, Title: "Old", Content: "Content"}
+		tags := []string{"go"}
+
+		mockRepo.On("FindByID", uint(1)).Return(existing, nil)
+		mockRepo.On("Update", mock.AnythingOfType("*entity.Post")).Return(nil)
+		mockCache.On("Delete", "all_posts").Return()
+		mockCache.On("DeletePrefix", "posts:").Return()
+
+		_, err := service.Update(1, post.Viewer{UserID: 1, Role: entity.RoleUser}, post.UpdatePostInput{Title: &existing.Title, Tags: &tags})
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "UpdateWithRevision", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockCache := new(MockCache)
+		service := post.NewService(mockRepo, mockCache)
+		existing := &entity.Post{ID: 1, UserID: 1, Title: "Old", Content: "Content"}
+
+		mockRepo.On("FindByID", uint(1)).Return(existing, nil)
+
+		result, err := service.Update(1, post.Viewer{UserID: 2, Role: entity.RoleUser}, post.UpdatePostInput{Title: &title})
+
+		assert.ErrorIs(t, err, post.ErrForbidden)
+		assert.Nil(t, result)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+		mockCache.AssertNotCalled(t, "Delete", "all_posts")
+	})
+}
+
+func TestDeleteOwned(t *testing.T) {
+	t.Run("Author", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockCache := new(MockCache)
+		service := post.NewService(mockRepo, mockCache)
+
+		mockRepo.On("FindByID", uint(1)).Return(&entity.Post{ID: 1, UserID: 1}, nil)
+		mockRepo.On("Delete", uint(1)).Return(nil)
+		mockCache.On("Delete", "all_posts").Return()
+		mockCache.On("DeletePrefix", "posts:").Return()
+
+		err := service.DeleteOwned(1, post.Viewer{UserID: 1, Role: entity.RoleUser})
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		mockCache.AssertExpectations(t)
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockCache := new(MockCache)
+		service := post.NewService(mockRepo, mockCache)
+
+		mockRepo.On("FindByID", uint(1)).Return(&entity.Post{ID: 1, UserID: 1}, nil)
+
+		err := service.DeleteOwned(1, post.Viewer{UserID: 2, Role: entity.RoleUser})
+
+		assert.ErrorIs(t, err, post.ErrForbidden)
+		mockRepo.AssertNotCalled(t, "Delete", uint(1))
+	})
+}
+
+func TestSetStatus(t *testing.T) {
+	author := post.Viewer{UserID: 1, Role: entity.RoleUser}
+
+	t.Run("Publish", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockCache := new(MockCache)
+		service := post.NewService(mockRepo, mockCache)
+
+		mockRepo.On("FindByID", uint(1)).Return(&entity.Post{ID: 1, UserID: 1, Status: entity.PostStatusDraft}, nil)
+		mockRepo.On("Update", mock.AnythingOfType("*entity.Post")).Return(nil)
+		mockCache.On("Delete", "all_posts").Return()
+		mockCache.On("DeletePrefix", "posts:").Return()
+
+		result, err := service.SetStatus(1, author, entity.PostStatusPublished)
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.PostStatusPublished, result.Status)
+		assert.NotNil(t, result.PublishedAt)
+		mockRepo.AssertExpectations(t)
+		mockCache.AssertExpectations(t)
+	})
+
+	t.Run("Unpublish Keeps PublishedAt", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockCache := new(MockCache)
+		service := post.NewService(mockRepo, mockCache)
+		publishedAt := time.Now().Add(-time.Hour)
+
+		mockRepo.On("FindByID", uint(1)).Return(&entity.Post{ID: 1, UserID: 1, Status: entity.PostStatusPublished, PublishedAt: &publishedAt}, nil)
+		mockRepo.On("Update", mock.AnythingOfType("*entity.Post")).Return(nil)
+		mockCache.On("Delete", "all_posts").Return()
+		mockCache.On("DeletePrefix", "posts:").Return()
+
+		result, err := service.SetStatus(1, author, entity.PostStatusDraft)
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.PostStatusDraft, result.Status)
+		assert.Equal(t, &publishedAt, result.PublishedAt)
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockCache := new(MockCache)
+		service := post.NewService(mockRepo, mockCache)
+
+		mockRepo.On("FindByID", uint(1)).Return(&entity.Post{ID: 1, UserID: 2, Status: entity.PostStatusDraft}, nil)
+
+		result, err := service.SetStatus(1, author, entity.PostStatusPublished)
+
+		assert.ErrorIs(t, err, post.ErrForbidden)
+		assert.Nil(t, result)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+}
+
+func TestSchedule(t *testing.T) {
+	author := post.Viewer{UserID: 1, Role: entity.RoleUser}
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockCache := new(MockCache)
+		service := post.NewService(mockRepo, mockCache)
+		publishAt := time.Now().Add(time.Hour)
+
+		mockRepo.On("FindByID", uint(1)).Return(&entity.Post{ID: 1, UserID: 1, Status: entity.PostStatusDraft}, nil)
+		mockRepo.On("Update", mock.MatchedBy(func(p *entity.Post) bool {
+			return p.Status == entity.PostStatusScheduled && p.PublishAt.Equal(publishAt)
+		})).Return(nil)
+		mockCache.On("Delete", "all_posts").Return()
+		mockCache.On("DeletePrefix", "posts:").Return()
+
+		result, err := service.Schedule(1, author, publishAt)
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.PostStatusScheduled, result.Status)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("In The Past", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockCache := new(MockCache)
+		service := post.NewService(mockRepo, mockCache)
+
+		result, err := service.Schedule(1, author, time.Now().Add(-time.Minute))
+
+		assert.ErrorIs(t, err, post.ErrInvalidSchedule)
+		assert.Nil(t, result)
+		mockRepo.AssertNotCalled(t, "FindByID", uint(1))
+	})
+
+	t.Run("On Create", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockCache := new(MockCache)
+		service := post.NewService(mockRepo, mockCache)
+		publishAt := time.Now().Add(time.Hour)
+		input := post.CreatePostInput{Title: "Later", Content: "Content", Status: entity.PostStatusPublished, PublishAt: &publishAt}
+
+		mockRepo.On("FindTakenSlugs", mock.Anything, uint(0)).Return([]string{}, nil)
+		mockRepo.On("Create", mock.MatchedBy(func(p *entity.Post) bool {
+			return p.Status == entity.PostStatusScheduled && p.PublishedAt == nil
+		})).Return(nil)
+		mockCache.On("Delete", "all_posts").Return()
+		mockCache.On("DeletePrefix", "posts:").Return()
+
+		_, err := service.Create(1, input)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestPublishDue(t *testing.T) {
+	t.Run("Published", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockCache := new(MockCache)
+		service := post.NewService(mockRepo, mockCache)
+
+		mockRepo.On("PublishDue", mock.AnythingOfType("time.Time"), 100).Return([]uint{1, 2}, nil)
+		mockCache.On("Delete", "all_posts").Return()
+		mockCache.On("DeletePrefix", "posts:").Return()
+
+		count, err := service.PublishDue()
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, count)
+		mockRepo.AssertExpectations(t)
+		mockCache.AssertExpectations(t)
+	})
+
+	t.Run("Nothing Due", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockCache := new(MockCache)
+		service := post.NewService(mockRepo, mockCache)
+
+		mockRepo.On("PublishDue", mock.AnythingOfType("time.Time"), 100).Return([]uint{}, nil)
+
+		count, err := service.PublishDue()
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, count)
+		mockCache.AssertNotCalled(t, "DeletePrefix", "posts:")
+	})
+}
+
+func TestSyncPublished(t *testing.T) {
+	since := time.Now().Add(-time.Minute)
+
+	t.Run("Published Elsewhere", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockCache := new(MockCache)
+		service := post.NewService(mockRepo, mockCache)
+
+		mockRepo.On("CountPublishedSince", since).Return(int64(1), nil)
+		mockCache.On("Delete", "all_posts").Return()
+		mockCache.On("DeletePrefix", "posts:").Return()
+
+		assert.NoError(t, service.SyncPublished(since))
+		mockCache.AssertExpectations(t)
+	})
+
+	t.Run("Nothing New", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockCache := new(MockCache)
+		service := post.NewService(mockRepo, mockCache)
+
+		mockRepo.On("CountPublishedSince", since).Return(int64(0), nil)
+
+		assert.NoError(t, service.SyncPublished(since))
+		mockCache.AssertNotCalled(t, "DeletePrefix", "posts:")
+	})
+}
+
+func TestRevisions(t *testing.T) {
+	author := post.Viewer{UserID: 1, Role: entity.RoleUser}
+	first := &entity.PostRevision{PostID: 1, Number: 1, Title: "Hello", Content: "one\ntwo\nthree", EditorID: 1}
+	second := &entity.PostRevision{PostID: 1, Number: 2, Title: "Hello", Content: "one\n2\nthree", EditorID: 1}
+
+	t.Run("List Forbidden", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockCache := new(MockCache)
+		service := post.NewService(mockRepo, mockCache)
+
+		mockRepo.On("FindByID", uint(1)).Return(&entity.Post{ID: 1, UserID: 1}, nil)
+
+		result, err := service.GetRevisions(1, post.Viewer{UserID: 2, Role: entity.RoleUser})
+
+		assert.ErrorIs(t, err, post.ErrForbidden)
+		assert.Nil(t, result)
+		mockRepo.AssertNotCalled(t, "FindRevisions", uint(1))
+	})
+
+	t.Run("Diff", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockCache := new(MockCache)
+		service := post.NewService(mockRepo, mockCache)
+
+		mockRepo.On("FindByID", uint(1)).Return(&entity.Post{ID: 1, UserID: 1}, nil)
+		mockRepo.On("FindRevision", uint(1), 1).Return(first, nil)
+		mockRepo.On("FindRevision", uint(1), 2).Return(second, nil)
+
+		result, err := service.DiffRevisions(1, author, 1, 2)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "--- revision 1\n+++ revision 2\n@@ -1,5 +1,5 @@\n Hello\n \n one\n-two\n+2\n three\n", result)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Diff Missing Revision", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockCache := new(MockCache)
+		service := post.NewService(mockRepo, mockCache)
+
+		mockRepo.On("FindByID", uint(1)).Return(&entity.Post{ID: 1, UserID: 1}, nil)
+		mockRepo.On("FindRevision", uint(1), 1).Return(first, nil)
+		mockRepo.On("FindRevision", uint(1), 9).Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := service.DiffRevisions(1, author, 1, 9)
+
+		assert.ErrorIs(t, err, pkgdb.ErrRecordNotFound)
+	})
+
+	t.Run("Restore", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockCache := new(MockCache)
+		service := post.NewService(mockRepo, mockCache)
+		current := &entity.Post{ID: 1, UserID: 1, Title: "Hello", Content: "one\n2\nthree"}
+
+		mockRepo.On("FindByID", uint(1)).Return(current, nil)
+		mockRepo.On("FindRevision", uint(1), 1).Return(first, nil)
+		mockRepo.On("UpdateWithRevision", mock.MatchedBy(func(p *entity.Post) bool {
+			return p.Content == first.Content
+		}), uint(1)).Return(nil)
+		mockCache.On("Delete", "all_posts").Return()
+		mockCache.On("DeletePrefix", "posts:").Return()
+
+		result, err := service.RestoreRevision(1, author, 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, first.Content, result.Content)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestSlugs(t *testing.T) {
+	author := post.Viewer{UserID: 1, Role: entity.RoleUser}
+
+	t.Run("Create Taken", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockCache := new(MockCache)
+		service := post.NewService(mockRepo, mockCache)
+
+		mockRepo.On("FindTakenSlugs", "hello-world", uint(0)).Return([]string{"hello-world", "hello-world-2"}, nil)
+		mockRepo.On("Create", mock.MatchedBy(func(p *entity.Post) bool {
+			return p.Slug == "hello-world-3"
+		})).Return(nil)
+		mockCache.On("Delete", "all_posts").Return()
+		mockCache.On("DeletePrefix", "posts:").Return()
+
+		result, err := service.Create(1, post.CreatePostInput{Title: "Hello, World!", Content: "Content"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "hello-world-3", result.Slug)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Create Retries Duplicate", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockCache := new(MockCache)
+		service := post.NewService(mockRepo, mockCache)
+
+		mockRepo.On("FindTakenSlugs", "hello", uint(0)).Return([]string{}, nil).Once()
+		mockRepo.On("Create", mock.AnythingOfType("*entity.Post")).Return(pkgdb.ErrDuplicateKey).Once()
+		mockRepo.On("FindTakenSlugs", "hello", uint(0)).Return([]string{"hello"}, nil).Once()
+		mockRepo.On("Create", mock.MatchedBy(func(p *entity.Post) bool {
+			return p.Slug == "hello-2"
+		})).Return(nil).Once()
+		mockCache.On("Delete", "all_posts").Return()
+		mockCache.On("DeletePrefix", "posts:").Return()
+
+		result, err := service.Create(1, post.CreatePostInput{Title: "Hello", Content: "Content"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "hello-2", result.Slug)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Update Keeps Own Suffix", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockCache := new(MockCache)
+		service := post.NewService(mockRepo, mockCache)
+		existing := &entity.Post{ID: 1, UserID: 1, Title: "Hello", Slug: "hello-2", Content: "Content"}
+		title := "hello!"
+
+		mockRepo.On("FindByID", uint(1)).Return(existing, nil)
+		mockRepo.On("FindTakenSlugs", "hello", uint(1)).Return([]string{"hello"}, nil)
+		mockRepo.On("UpdateWithRevision", mock.MatchedBy(func(p *entity.Post) bool {
+			return p.Slug == "hello-2"
+		}), uint(1)).Return(nil)
+		mockCache.On("Delete", "all_posts").Return()
+		mockCache.On("DeletePrefix", "posts:").Return()
+
+		_, err := service.Update(1, author, post.UpdatePostInput{Title: &title})
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Get Current", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := post.NewService(mockRepo, new(MockCache))
+		published := &entity.Post{ID: 1, UserID: 1, Slug: "hello", Status: entity.PostStatusPublished}
+
+		mockRepo.On("FindBySlug", "hello").Return(published, nil)
+		mockRepo.On("FindReactions", mock.Anything, mock.Anything).Return(map[uint]post.ReactionSummary{}, nil)
+
+		result, err := service.GetBySlug("hello", post.Viewer{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, published, result)
+		mockRepo.AssertNotCalled(t, "FindPostIDByOldSlug", "hello")
+	})
+
+	t.Run("Get Old", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := post.NewService(mockRepo, new(MockCache))
+		published := &entity.Post{ID: 1, UserID: 1, Slug: "hello-again", Status: entity.PostStatusPublished}
+
+		mockRepo.On("FindBySlug", "hello").Return(nil, gorm.ErrRecordNotFound)
+		mockRepo.On("FindPostIDByOldSlug", "hello").Return(uint(1), nil)
+		mockRepo.On("FindByID", uint(1)).Return(published, nil)
+		mockRepo.On("FindReactions", mock.Anything, mock.Anything).Return(map[uint]post.ReactionSummary{}, nil)
+
+		result, err := service.GetBySlug("hello", post.Viewer{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "hello-again", result.Slug)
+	})
+
+	t.Run("Get Hidden Draft", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := post.NewService(mockRepo, new(MockCache))
+		draft := &entity.Post{ID: 1, UserID: 1, Slug: "hello", Status: entity.PostStatusDraft}
+
+		mockRepo.On("FindBySlug", "hello").Return(draft, nil)
+
+		result, err := service.GetBySlug("hello", post.Viewer{UserID: 2, Role: entity.RoleUser})
+
+		assert.ErrorIs(t, err, pkgdb.ErrRecordNotFound)
+		assert.Nil(t, result)
+	})
+}
+
+func TestReactions(t *testing.T) {
+	reader := post.Viewer{UserID: 4, Role: entity.RoleUser}
+	published := &entity.Post{ID: 1, UserID: 1, Status: entity.PostStatusPublished}
+
+	t.Run("React", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := post.NewService(mockRepo, new(MockCache))
+
+		mockRepo.On("FindByID", uint(1)).Return(published, nil)
+		mockRepo.On("AddReaction", &entity.PostReaction{PostID: 1, UserID: 4, Kind: entity.ReactionLike}).Return(nil)
+		mockRepo.On("FindReactions", []uint{1}, uint(4)).Return(map[uint]post.ReactionSummary{
+			1: {
+				Counts: map[entity.ReactionKind]int64{entity.ReactionLike: 3, entity.ReactionWow: 1},
+				Mine:   []entity.ReactionKind{entity.ReactionLike},
+			},
+		}, nil)
+
+		result, err := service.React(1, reader, entity.ReactionLike)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), result.Counts[entity.ReactionLike])
+		assert.Equal(t, []entity.ReactionKind{entity.ReactionLike}, result.Mine)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Unreact", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := post.NewService(mockRepo, new(MockCache))
+
+		mockRepo.On("FindByID", uint(1)).Return(published, nil)
+		mockRepo.On("RemoveReaction", uint(1), uint(4), entity.ReactionLike).Return(nil)
+		mockRepo.On("FindReactions", []uint{1}, uint(4)).Return(map[uint]post.ReactionSummary{}, nil)
+
+		result, err := service.Unreact(1, reader, entity.ReactionLike)
+
+		assert.NoError(t, err)
+		assert.Empty(t, result.Counts)
+		assert.NotNil(t, result.Mine)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Unknown Kind", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := post.NewService(mockRepo, new(MockCache))
+
+		_, err := service.React(1, reader, entity.ReactionKind("meh"))
+
+		assert.ErrorIs(t, err, post.ErrInvalidReaction)
+		mockRepo.AssertNotCalled(t, "AddReaction", mock.Anything)
+	})
+
+	t.Run("Hidden Post", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := post.NewService(mockRepo, new(MockCache))
+
+		mockRepo.On("FindByID", uint(1)).Return(&entity.Post{ID: 1, UserID: 1, Status: entity.PostStatusDraft}, nil)
+
+		_, err := service.React(1, reader, entity.ReactionLike)
+
+		assert.ErrorIs(t, err, pkgdb.ErrRecordNotFound)
+		mockRepo.AssertNotCalled(t, "AddReaction", mock.Anything)
+	})
 }
@@ -0,0 +1,44 @@
+package post
+
+import (
+	"post/internal/entity"
+	"post/internal/user"
+)
+
+// View is a post as the API returns it, with its author shown the way the
+// viewer may see them.
+type View struct {
+	entity.Post
+	User any `json:"user,omitempty"`
+}
+
+// SearchResultView is a search result as the API returns it.
+type SearchResultView struct {
+	View
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
+
+func NewView(post *entity.Post, viewer Viewer) View {
+	return View{Post: *post, User: user.View(&post.User, viewer.UserID, viewer.Role)}
+}
+
+func NewViews(posts []entity.Post, viewer Viewer) []View {
+	views := make([]View, len(posts))
+	for i := range posts {
+		views[i] = NewView(&posts[i], viewer)
+	}
+	return views
+}
+
+func newSearchResultViews(results []SearchResult, viewer Viewer) []SearchResultView {
+	views := make([]SearchResultView, len(results))
+	for i := range results {
+		views[i] = SearchResultView{
+			View:    NewView(&results[i].Post, viewer),
+			Rank:    results[i].Rank,
+			Snippet: results[i].Snippet,
+		}
+	}
+	return views
+}
@@ -0,0 +1,55 @@
+package post
+
+import (
+	"fmt"
+
+	"post/internal/entity"
+)
+
+// Viewer is the caller a post is read or changed on behalf of.
+// The zero Viewer is an anonymous reader.
+type Viewer struct {
+	UserID uint
+	Role   entity.Role
+}
+
+// Can reports whether the viewer is signed in with a role that grants the
+// permission.
+func (v Viewer) Can(perm entity.Permission) bool {
+	return v.UserID != 0 && v.Role.Can(perm)
+}
+
+// CanManage reports whether the viewer is the post's author or may edit any
+// post.
+func (v Viewer) CanManage(post *entity.Post) bool {
+	return v.owns(post) || v.Can(entity.PermPostsUpdateAny)
+}
+
+// CanDelete reports whether the viewer is the post's author or may delete
+// any post.
+func (v Viewer) CanDelete(post *entity.Post) bool {
+	return v.owns(post) || v.Can(entity.PermPostsDeleteAny)
+}
+
+// CanSee reports whether the viewer may read the post. Only published posts
+// are public, everything else is limited to the author and roles that may
+// read any post.
+func (v Viewer) CanSee(post *entity.Post) bool {
+	return post.Status == entity.PostStatusPublished || v.owns(post) || v.Can(entity.PermPostsReadAny)
+}
+
+func (v Viewer) owns(post *entity.Post) bool {
+	return v.UserID != 0 && post.UserID == v.UserID
+}
+
+// scope is the part of a list cache key that depends on who is looking.
+func (v Viewer) scope() string {
+	switch {
+	case v.Can(entity.PermPostsReadAny):
+		return "all"
+	case v.UserID != 0:
+		return fmt.Sprintf("user%d", v.UserID)
+	default:
+		return "public"
+	}
+}
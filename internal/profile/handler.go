@@ -1,8 +1,10 @@
 package profile
 
 import (
+	"errors"
 	"net/http"
 
+	pkgdb "post/internal/pkg/database"
 	"post/internal/pkg/response"
 
 	"github.com/gin-gonic/gin"
@@ -16,6 +18,8 @@ func NewHandler(service Service) *Handler {
 	return &Handler{service}
 }
 
+// UpsertProfile creates the caller's profile or changes the fields given in
+// the body.
 func (h *Handler) UpsertProfile(c *gin.Context) {
 	userID := c.MustGet("userID").(uint)
 
@@ -27,7 +31,7 @@ func (h *Handler) UpsertProfile(c *gin.Context) {
 
 	profile, err := h.service.CreateOrUpdate(userID, input)
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, "Failed to update profile", err.Error())
+		writeError(c, err, "Failed to update profile")
 		return
 	}
 
@@ -45,3 +49,27 @@ func (h *Handler) GetProfile(c *gin.Context) {
 
 	response.Success(c, http.StatusOK, "Profile retrieved", profile)
 }
+
+// GetProfileByHandle returns anyone's profile by @handle.
+func (h *Handler) GetProfileByHandle(c *gin.Context) {
+	profile, err := h.service.GetByHandle(c.Param("handle"))
+	if err != nil {
+		writeError(c, err, "Failed to fetch profile")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Profile retrieved", profile)
+}
+
+func writeError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, pkgdb.ErrRecordNotFound):
+		response.Error(c, http.StatusNotFound, "Profile not found", nil)
+	case errors.Is(err, ErrInvalidHandle):
+		response.Error(c, http.StatusBadRequest, "Invalid input", err.Error())
+	case errors.Is(err, ErrHandleTaken):
+		response.Error(c, http.StatusConflict, "Handle taken", err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, message, err.Error())
+	}
+}
@@ -10,6 +10,7 @@ type Repository interface {
 	Create(profile *entity.Profile) error
 	Update(profile *entity.Profile) error
 	FindByUserID(userID uint) (*entity.Profile, error)
+	FindByHandle(handle string) (*entity.Profile, error)
 }
 
 type repository struct {
@@ -33,3 +34,13 @@ func (r *repository) FindByUserID(userID uint) (*entity.Profile, error) {
 	err := r.db.Where("user_id = ?", userID).First(&profile).Error
 	return &profile, err
 }
+
+// FindByHandle returns the profile with the given lower-case handle, unless
+// its user was deleted.
+func (r *repository) FindByHandle(handle string) (*entity.Profile, error) {
+	var profile entity.Profile
+	err := r.db.Joins("JOIN users ON users.id = profiles.user_id AND users.deleted_at IS NULL").
+		Where("profiles.handle = ?", handle).
+		First(&profile).Error
+	return &profile, err
+}
@@ -1,52 +1,165 @@
 package profile
 
 import (
+	"errors"
+	"regexp"
+	"strings"
+
 	"post/internal/entity"
+	"post/internal/pkg/cache"
+	pkgdb "post/internal/pkg/database"
+)
+
+var (
+	ErrInvalidHandle = errors.New("handle must be 3 to 30 letters, digits or underscores")
+	ErrHandleTaken   = errors.New("handle is already taken")
 )
 
+var handleRe = regexp.MustCompile(`^[a-z0-9_]{3,30}$`)
+
 type Service interface {
 	CreateOrUpdate(userID uint, input ProfileInput) (*entity.Profile, error)
 	GetByUserID(userID uint) (*entity.Profile, error)
+	GetByHandle(handle string) (*entity.Profile, error)
 }
 
 type service struct {
-	repo Repository
+	repo  Repository
+	cache cache.Cache
 }
 
-func NewService(repo Repository) Service {
-	return &service{repo}
+// NewService returns a profile service sharing the posts cache. Cached post
+// lists and feeds carry their authors' profiles, so they are dropped
+// whenever a profile changes.
+func NewService(repo Repository, cache cache.Cache) Service {
+	return &service{repo, cache}
 }
 
+// ProfileInput changes the fields that are set, leaving the others as they
+// are. An empty string clears a field, and an empty handle removes it. URLs
+// must use http or https.
 type ProfileInput struct {
-	Name string `json:"name" binding:"required"`
-	Bio  string `json:"bio"`
+	Name        *string   `json:"name" binding:"omitempty,max=100"`
+	Handle      *string   `json:"handle" binding:"omitempty,max=31"`
+	Bio         *string   `json:"bio" binding:"omitempty,max=1000"`
+	AvatarURL   *string   `json:"avatar_url" binding:"omitempty,len=0|http_url,max=2048"`
+	Website     *string   `json:"website" binding:"omitempty,len=0|http_url,max=2048"`
+	Location    *string   `json:"location" binding:"omitempty,max=100"`
+	Pronouns    *string   `json:"pronouns" binding:"omitempty,max=40"`
+	SocialLinks *[]string `json:"social_links" binding:"omitempty,max=10,dive,http_url,max=2048"`
 }
 
 func (s *service) CreateOrUpdate(userID uint, input ProfileInput) (*entity.Profile, error) {
+	var handle *string
+	if input.Handle != nil {
+		var err error
+		if handle, err = s.checkHandle(userID, *input.Handle); err != nil {
+			return nil, err
+		}
+	}
+
 	profile, err := s.repo.FindByUserID(userID)
 	if err != nil {
 		// Create new if not exists (assuming error means not found, handled better with specific error check usually)
 		// Simpler logic:
-		newProfile := &entity.Profile{
-			UserID: userID,
-			Name:   input.Name,
-			Bio:    input.Bio,
-		}
+		newProfile := &entity.Profile{UserID: userID, SocialLinks: []string{}}
+		apply(newProfile, input, handle)
 		if err := s.repo.Create(newProfile); err != nil {
-			return nil, err
+			return nil, handleError(err, input)
 		}
+		s.invalidate()
 		return newProfile, nil
 	}
 
 	// Update existing
-	profile.Name = input.Name
-	profile.Bio = input.Bio
+	apply(profile, input, handle)
 	if err := s.repo.Update(profile); err != nil {
-		return nil, err
+		return nil, handleError(err, input)
 	}
+	s.invalidate()
 	return profile, nil
 }
 
+// invalidate drops the cached posts, the same way post writes do.
+func (s *service) invalidate() {
+	s.cache.Delete("all_posts")
+	s.cache.DeletePrefix("posts:")
+}
+
 func (s *service) GetByUserID(userID uint) (*entity.Profile, error) {
 	return s.repo.FindByUserID(userID)
 }
+
+// GetByHandle finds a profile by its handle, with or without the leading @
+// and in any case.
+func (s *service) GetByHandle(handle string) (*entity.Profile, error) {
+	profile, err := s.repo.FindByHandle(normalizeHandle(handle))
+	if err != nil {
+		return nil, pkgdb.ParseError(err)
+	}
+	return profile, nil
+}
+
+// checkHandle validates a requested handle and makes sure no other user has
+// it. It returns the handle to store, nil when it is being removed.
+func (s *service) checkHandle(userID uint, requested string) (*string, error) {
+	handle := normalizeHandle(requested)
+	if handle == "" {
+		return nil, nil
+	}
+	if !handleRe.MatchString(handle) {
+		return nil, ErrInvalidHandle
+	}
+
+	owner, err := s.repo.FindByHandle(handle)
+	if err == nil && owner.UserID != userID {
+		return nil, ErrHandleTaken
+	}
+	if err != nil && pkgdb.ParseError(err) != pkgdb.ErrRecordNotFound {
+		return nil, err
+	}
+	return &handle, nil
+}
+
+// apply copies the set fields of input onto profile.
+func apply(profile *entity.Profile, input ProfileInput, handle *string) {
+	if input.Handle != nil {
+		profile.Handle = handle
+	}
+	if input.Name != nil {
+		profile.Name = *input.Name
+	}
+	if input.Bio != nil {
+		profile.Bio = *input.Bio
+	}
+	if input.AvatarURL != nil {
+		profile.AvatarURL = *input.AvatarURL
+	}
+	if input.Website != nil {
+		profile.Website = *input.Website
+	}
+	if input.Location != nil {
+		profile.Location = *input.Location
+	}
+	if input.Pronouns != nil {
+		profile.Pronouns = *input.Pronouns
+	}
+	if input.SocialLinks != nil {
+		profile.SocialLinks = *input.SocialLinks
+	}
+	if profile.SocialLinks == nil {
+		profile.SocialLinks = []string{}
+	}
+}
+
+// handleError reports a lost race for a handle as ErrHandleTaken.
+func handleError(err error, input ProfileInput) error {
+	if input.Handle != nil && pkgdb.ParseError(err) == pkgdb.ErrDuplicateKey {
+		return ErrHandleTaken
+	}
+	return err
+}
+
+func normalizeHandle(handle string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(handle), "@"))
+}
@@ -5,10 +5,13 @@ import (
 	"testing"
 
 	"post/internal/entity"
+	"post/internal/pkg/cache"
+	pkgdb "post/internal/pkg/database"
 	"post/internal/profile"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 )
 
 // MockRepository is a mock of profile.Repository
@@ -34,51 +37,199 @@ func (m *MockRepository) FindByUserID(userID uint) (*entity.Profile, error) {
 	return args.Get(0).(*entity.Profile), args.Error(1)
 }
 
+func (m *MockRepository) FindByHandle(handle string) (*entity.Profile, error) {
+	args := m.Called(handle)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Profile), args.Error(1)
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func newCache() cache.Cache {
+	c, _ := cache.NewLRUCache(10)
+	return c
+}
+
 func TestCreateOrUpdate(t *testing.T) {
 	t.Run("CreateNew", func(t *testing.T) {
 		mockRepo := new(MockRepository)
-		service := profile.NewService(mockRepo)
+		service := profile.NewService(mockRepo, newCache())
 		userID := uint(1)
-		input := profile.ProfileInput{Name: "New User", Bio: "Hello"}
+		input := profile.ProfileInput{Name: strPtr("New User"), Bio: strPtr("Hello")}
 
 		mockRepo.On("FindByUserID", userID).Return(nil, errors.New("not found"))
 		mockRepo.On("Create", mock.MatchedBy(func(p *entity.Profile) bool {
-			return p.UserID == userID && p.Name == input.Name && p.Bio == input.Bio
+			return p.UserID == userID && p.Name == *input.Name && p.Bio == *input.Bio
 		})).Return(nil)
 
 		result, err := service.CreateOrUpdate(userID, input)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, result)
-		assert.Equal(t, input.Name, result.Name)
+		assert.Equal(t, *input.Name, result.Name)
+		assert.NotNil(t, result.SocialLinks)
 		mockRepo.AssertExpectations(t)
 	})
 
 	t.Run("UpdateExisting", func(t *testing.T) {
 		mockRepo := new(MockRepository)
-		service := profile.NewService(mockRepo)
+		service := profile.NewService(mockRepo, newCache())
 		userID := uint(1)
-		input := profile.ProfileInput{Name: "Updated User", Bio: "Updated Bio"}
+		input := profile.ProfileInput{Name: strPtr("Updated User"), Bio: strPtr("Updated Bio")}
 		existingProfile := &entity.Profile{UserID: userID, Name: "Old Name", Bio: "Old Bio"}
 
 		mockRepo.On("FindByUserID", userID).Return(existingProfile, nil)
 		mockRepo.On("Update", mock.MatchedBy(func(p *entity.Profile) bool {
-			return p.UserID == userID && p.Name == input.Name && p.Bio == input.Bio
+			return p.UserID == userID && p.Name == *input.Name && p.Bio == *input.Bio
 		})).Return(nil)
 
 		result, err := service.CreateOrUpdate(userID, input)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, result)
-		assert.Equal(t, input.Name, result.Name)
+		assert.Equal(t, *input.Name, result.Name)
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("Partial", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := profile.NewService(mockRepo, newCache())
+		existing := &entity.Profile{UserID: 1, Name: "Name", Bio: "Bio", Website: "https://old.example"}
+
+		mockRepo.On("FindByUserID", uint(1)).Return(existing, nil)
+		mockRepo.On("Update", mock.Anything).Return(nil)
+
+		result, err := service.CreateOrUpdate(1, profile.ProfileInput{
+			Website:     strPtr(""),
+			SocialLinks: &[]string{"https://github.com/someone"},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Name", result.Name)
+		assert.Equal(t, "Bio", result.Bio)
+		assert.Empty(t, result.Website)
+		assert.Equal(t, []string{"https://github.com/someone"}, result.SocialLinks)
+	})
+
+	t.Run("Handle", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := profile.NewService(mockRepo, newCache())
+
+		mockRepo.On("FindByHandle", "jane_doe").Return(nil, gorm.ErrRecordNotFound)
+		mockRepo.On("FindByUserID", uint(1)).Return(&entity.Profile{UserID: 1}, nil)
+		mockRepo.On("Update", mock.Anything).Return(nil)
+
+		result, err := service.CreateOrUpdate(1, profile.ProfileInput{Handle: strPtr("@Jane_Doe")})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "jane_doe", *result.Handle)
+	})
+
+	t.Run("Keep Own Handle", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := profile.NewService(mockRepo, newCache())
+		own := &entity.Profile{UserID: 1, Handle: strPtr("jane")}
+
+		mockRepo.On("FindByHandle", "jane").Return(own, nil)
+		mockRepo.On("FindByUserID", uint(1)).Return(own, nil)
+		mockRepo.On("Update", mock.Anything).Return(nil)
+
+		_, err := service.CreateOrUpdate(1, profile.ProfileInput{Handle: strPtr("jane")})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("Remove Handle", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := profile.NewService(mockRepo, newCache())
+
+		mockRepo.On("FindByUserID", uint(1)).Return(&entity.Profile{UserID: 1, Handle: strPtr("jane")}, nil)
+		mockRepo.On("Update", mock.Anything).Return(nil)
+
+		result, err := service.CreateOrUpdate(1, profile.ProfileInput{Handle: strPtr("")})
+
+		assert.NoError(t, err)
+		assert.Nil(t, result.Handle)
+	})
+
+	t.Run("Handle Taken", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := profile.NewService(mockRepo, newCache())
+
+		mockRepo.On("FindByHandle", "jane").Return(&entity.Profile{UserID: 2}, nil)
+
+		_, err := service.CreateOrUpdate(1, profile.ProfileInput{Handle: strPtr("jane")})
+
+		assert.ErrorIs(t, err, profile.ErrHandleTaken)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("Invalid Handle", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := profile.NewService(mockRepo, newCache())
+
+		_, err := service.CreateOrUpdate(1, profile.ProfileInput{Handle: strPtr("jane doe")})
+
+		assert.ErrorIs(t, err, profile.ErrInvalidHandle)
+		mockRepo.AssertNotCalled(t, "FindByHandle", mock.Anything)
+	})
+}
+
+func TestCreateOrUpdateInvalidatesPosts(t *testing.T) {
+	mockRepo := new(MockRepository)
+	postCache := newCache()
+	service := profile.NewService(mockRepo, postCache)
+	existing := &entity.Profile{UserID: 1, Name: "Old Name"}
+	postCache.Set("posts:feeds:/feeds/users/1/posts.rss", "feed")
+	postCache.Set("posts:public:page:1:20:", "page")
+
+	mockRepo.On("FindByUserID", uint(1)).Return(existing, nil)
+	mockRepo.On("Update", existing).Return(nil)
+
+	_, err := service.CreateOrUpdate(1, profile.ProfileInput{Name: strPtr("New Name")})
+
+	assert.NoError(t, err)
+	_, ok := postCache.Get("posts:feeds:/feeds/users/1/posts.rss")
+	assert.False(t, ok)
+	_, ok = postCache.Get("posts:public:page:1:20:")
+	assert.False(t, ok)
+}
+
+func TestGetByHandle(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := profile.NewService(mockRepo, newCache())
+		expected := &entity.Profile{UserID: 1, Handle: strPtr("jane")}
+
+		mockRepo.On("FindByHandle", "jane").Return(expected, nil)
+
+		result, err := service.GetByHandle("@Jane")
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, result)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := profile.NewService(mockRepo, newCache())
+
+		mockRepo.On("FindByHandle", "nobody").Return(nil, gorm.ErrRecordNotFound)
+
+		result, err := service.GetByHandle("nobody")
+
+		assert.ErrorIs(t, err, pkgdb.ErrRecordNotFound)
+		assert.Nil(t, result)
+	})
 }
 
 func TestGetByUserID(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		mockRepo := new(MockRepository)
-		service := profile.NewService(mockRepo)
+		service := profile.NewService(mockRepo, newCache())
 		userID := uint(1)
 		expectedProfile := &entity.Profile{UserID: userID, Name: "Test User"}
 
@@ -93,7 +244,7 @@ func TestGetByUserID(t *testing.T) {
 
 	t.Run("NotFound", func(t *testing.T) {
 		mockRepo := new(MockRepository)
-		service := profile.NewService(mockRepo)
+		service := profile.NewService(mockRepo, newCache())
 		userID := uint(2)
 		mockRepo.On("FindByUserID", userID).Return(nil, errors.New("profile not found"))
 
@@ -1,22 +1,35 @@
 package router
 
 import (
+	"log"
 	"net/http"
 	"post/internal/auth"
+	"post/internal/author"
+	"post/internal/bookmark"
+	"post/internal/comment"
 	"post/internal/dashboard"
+	"post/internal/entity"
+	"post/internal/follow"
+	"post/internal/media"
 	"post/internal/pkg/cache"
 	"post/internal/pkg/config"
 	"post/internal/pkg/database"
+	"post/internal/pkg/imageproc"
 	"post/internal/pkg/middleware"
 	"post/internal/pkg/response"
+	"post/internal/pkg/storage"
 	"post/internal/post"
 	"post/internal/profile"
+	"post/internal/sitemap"
+	"post/internal/syndication"
+	"post/internal/tag"
 	"post/internal/user"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-func Init(cfg *config.Config) *gin.Engine {
+func Init(cfg *config.Config, postCache cache.Cache) *gin.Engine {
 	// Initialize Gin
 	if cfg.App.Env == "production" {
 		gin.SetMode(gin.ReleaseMode)
@@ -37,31 +50,69 @@ func Init(cfg *config.Config) *gin.Engine {
 
 	// Repositories
 	userRepo := user.NewRepository(db)
+	authRepo := auth.NewRepository(db)
 	profileRepo := profile.NewRepository(db)
 	postRepo := post.NewRepository(db)
+	tagRepo := tag.NewRepository(db)
+	commentRepo := comment.NewRepository(db)
+	bookmarkRepo := bookmark.NewRepository(db)
+	followRepo := follow.NewRepository(db)
+	mediaRepo := media.NewRepository(db)
 
-	// Services
-	jwtService := auth.NewJWTService(cfg)
-	authService := auth.NewService(userRepo, jwtService)
-	userService := user.NewService(userRepo)
-	profileService := profile.NewService(profileRepo)
+	// Storage
+	mediaStorage, err := storage.New(cfg.Media, cfg.App.BaseURL)
+	if err != nil {
+		log.Fatalf("Failed to set up media storage: %v", err)
+	}
 
-	// Initialize Cache (100 items)
-	postCache, err := cache.NewLRUCache(100)
+	// Revocation checks run on every authenticated request, so they get
+	// their own cache instead of competing with posts for space
+	revocationCache, err := cache.NewLRUCache(10000)
+	if err != nil {
+		log.Fatalf("Failed to create revocation cache: %v", err)
+	}
+	revocations := auth.NewRevocationStore(authRepo, revocationCache, time.Duration(max(cfg.JWT.Expiry, cfg.JWT.RefreshExpiry))*time.Hour)
+
+	// Services
+	jwtService, err := auth.NewJWTService(cfg)
 	if err != nil {
-		panic(err)
+		log.Fatalf("Failed to set up JWT signing: %v", err)
 	}
+	authService := auth.NewService(userRepo, authRepo, revocations, jwtService)
+	userService := user.NewService(userRepo, revocations)
+	profileService := profile.NewService(profileRepo, postCache)
+	tagService := tag.NewService(tagRepo)
+
 	postService := post.NewService(postRepo, postCache)
-	// Wait, Check post service implementation. It only took repo. Good.
+	commentService := comment.NewService(commentRepo, postService, cfg.Comment.MaxDepth)
+	bookmarkService := bookmark.NewService(bookmarkRepo, postService)
+	followService := follow.NewService(followRepo, userService)
+	mediaService := media.NewService(mediaRepo, mediaStorage, postService, cfg.Media.MaxSize, cfg.Media.Quota, imageproc.Options{
+		MaxWidth: cfg.Media.Images.MaxWidth,
+		Widths:   cfg.Media.Images.ThumbnailWidths,
+		Quality:  cfg.Media.Images.Quality,
+	})
+	authorService := author.NewService(userService, profileService, postService)
+	sitemapService := sitemap.NewService(postRepo, userRepo, postCache, cfg.App.BaseURL)
+	syndicationService := syndication.NewService(postService, userService, profileService, postCache, cfg.App.Name, cfg.App.BaseURL)
 
 	// Handlers
 	authHandler := auth.NewHandler(authService)
 	userHandler := user.NewHandler(userService)
 	profileHandler := profile.NewHandler(profileService)
 	postHandler := post.NewHandler(postService)
+	tagHandler := tag.NewHandler(tagService)
+	commentHandler := comment.NewHandler(commentService)
+	bookmarkHandler := bookmark.NewHandler(bookmarkService)
+	followHandler := follow.NewHandler(followService)
+	authorHandler := author.NewHandler(authorService)
+	syndicationHandler := syndication.NewHandler(syndicationService)
+	sitemapHandler := sitemap.NewHandler(sitemapService)
+	mediaHandler := media.NewHandler(mediaService, cfg.Media.MaxSize)
 
 	// Auth Middleware
-	authMiddleware := auth.Middleware(jwtService)
+	authMiddleware := auth.Middleware(jwtService, authService, cfg.JWT.SilentRefresh)
+	optionalAuthMiddleware := auth.OptionalMiddleware(jwtService, authService, cfg.JWT.SilentRefresh)
 
 	// Routes
 	api := r.Group("/api")
@@ -71,41 +122,139 @@ func Init(cfg *config.Config) *gin.Engine {
 		{
 			authRoutes.POST("/signup", authHandler.Signup)
 			authRoutes.POST("/signin", authHandler.Signin)
+			authRoutes.POST("/refresh", authHandler.Refresh)
+			authRoutes.POST("/logout", authMiddleware, authHandler.Logout)
+			authRoutes.POST("/logout-all", authMiddleware, authHandler.LogoutAll)
+			authRoutes.GET("/sessions", authMiddleware, authHandler.GetSessions)
+			authRoutes.DELETE("/sessions/:id", authMiddleware, authHandler.DeleteSession)
 		}
 
 		// User
+		// Author pages are public
+		api.GET("/users/:id/posts", optionalAuthMiddleware, authorHandler.GetAuthorPosts)
+		api.GET("/profiles/:handle/posts", optionalAuthMiddleware, authorHandler.GetAuthorPostsByHandle)
+
 		userRoutes := api.Group("/users")
 		userRoutes.Use(authMiddleware)
 		{
 			userRoutes.GET("/me", userHandler.GetProfile)
+			userRoutes.GET("/me/bookmarks", bookmarkHandler.GetMyBookmarks)
 			userRoutes.GET("/:id", userHandler.GetUserByID)
+			userRoutes.PUT("/:id/role", auth.RequirePermission(entity.PermUsersUpdateRole), userHandler.SetRole)
+			userRoutes.PUT("/:id/follow", followHandler.FollowUser)
+			userRoutes.DELETE("/:id/follow", followHandler.UnfollowUser)
+			userRoutes.GET("/:id/followers", followHandler.GetFollowers)
+			userRoutes.GET("/:id/following", followHandler.GetFollowing)
 		}
 
+		// Roles
+		api.GET("/roles", authMiddleware, auth.RequirePermission(entity.PermUsersUpdateRole), userHandler.GetRoles)
+
+		// Feed
+		api.GET("/feed", authMiddleware, postHandler.GetFeed)
+
 		// Profile
 		profileRoutes := api.Group("/profile")
 		profileRoutes.Use(authMiddleware)
 		{
 			profileRoutes.GET("/", profileHandler.GetProfile)
 			profileRoutes.PUT("/", profileHandler.UpsertProfile)
+			profileRoutes.PATCH("/", profileHandler.UpsertProfile)
+		}
+		api.GET("/profiles/:handle", profileHandler.GetProfileByHandle)
+
+		// Tag
+		tagRoutes := api.Group("/tags")
+		{
+			tagRoutes.GET("/", tagHandler.GetAllTags)
 		}
 
 		// Post
 		postRoutes := api.Group("/posts")
 		{
-			postRoutes.GET("/", postHandler.GetAllPosts)
-			postRoutes.GET("/:id", postHandler.GetPostByID)
+			postRoutes.GET("/", optionalAuthMiddleware, postHandler.GetAllPosts)
+			postRoutes.GET("/search", optionalAuthMiddleware, postHandler.SearchPosts)
+			postRoutes.GET("/by-slug/:slug", optionalAuthMiddleware, postHandler.GetPostBySlug)
+			postRoutes.GET("/:id", optionalAuthMiddleware, postHandler.GetPostByID)
+			postRoutes.GET("/:id/comments", optionalAuthMiddleware, commentHandler.GetComments)
+			postRoutes.GET("/:id/images", optionalAuthMiddleware, mediaHandler.GetPostImages)
 
 			// Protected
 			postRoutes.Use(authMiddleware)
 			postRoutes.POST("/", postHandler.CreatePost)
+			postRoutes.PUT("/:id", postHandler.ReplacePost)
+			postRoutes.PATCH("/:id", postHandler.UpdatePost)
+			postRoutes.DELETE("/:id", postHandler.DeletePost)
+			postRoutes.POST("/:id/publish", postHandler.PublishPost)
+			postRoutes.POST("/:id/unpublish", postHandler.UnpublishPost)
+			postRoutes.POST("/:id/archive", postHandler.ArchivePost)
+			postRoutes.POST("/:id/schedule", postHandler.SchedulePost)
+			postRoutes.GET("/:id/revisions", postHandler.GetRevisions)
+			postRoutes.GET("/:id/revisions/diff", postHandler.DiffRevisions)
+			postRoutes.POST("/:id/revisions/:rev/restore", postHandler.RestoreRevision)
+			postRoutes.POST("/:id/comments", commentHandler.CreateComment)
+			postRoutes.PUT("/:id/reactions/:kind", postHandler.AddReaction)
+			postRoutes.DELETE("/:id/reactions/:kind", postHandler.RemoveReaction)
+			postRoutes.PUT("/:id/bookmark", bookmarkHandler.AddBookmark)
+			postRoutes.DELETE("/:id/bookmark", bookmarkHandler.RemoveBookmark)
+			postRoutes.POST("/:id/images", mediaHandler.UploadPostImage)
+		}
+
+		// Media
+		mediaRoutes := api.Group("/media")
+		mediaRoutes.Use(authMiddleware)
+		{
+			mediaRoutes.POST("/", mediaHandler.UploadMedia)
+			mediaRoutes.GET("/", mediaHandler.GetMyMedia)
+			mediaRoutes.GET("/:id", mediaHandler.GetMedia)
+			mediaRoutes.DELETE("/:id", mediaHandler.DeleteMedia)
+		}
+
+		// Comment
+		commentRoutes := api.Group("/comments")
+		commentRoutes.Use(authMiddleware)
+		{
+			commentRoutes.PATCH("/:id", commentHandler.UpdateComment)
+			commentRoutes.DELETE("/:id", commentHandler.DeleteComment)
 		}
 	}
 
+	// Feeds
+	feedRoutes := r.Group("/feeds")
+	{
+		feedRoutes.GET("/posts.rss", syndicationHandler.GetPostsFeed)
+		feedRoutes.GET("/posts.atom", syndicationHandler.GetPostsFeed)
+		feedRoutes.GET("/posts.json", syndicationHandler.GetPostsFeed)
+		feedRoutes.GET("/users/:id/posts.rss", syndicationHandler.GetAuthorFeed)
+		feedRoutes.GET("/users/:id/posts.atom", syndicationHandler.GetAuthorFeed)
+		feedRoutes.GET("/users/:id/posts.json", syndicationHandler.GetAuthorFeed)
+		feedRoutes.GET("/tags/:tag/posts.rss", syndicationHandler.GetTagFeed)
+		feedRoutes.GET("/tags/:tag/posts.atom", syndicationHandler.GetTagFeed)
+		feedRoutes.GET("/tags/:tag/posts.json", syndicationHandler.GetTagFeed)
+	}
+
+	// Uploaded files, when stored locally. nosniff keeps browsers from
+	// treating them as anything but their stored type.
+	if cfg.Media.Driver == "local" {
+		files := r.Group("/media")
+		files.Use(func(c *gin.Context) {
+			c.Header("X-Content-Type-Options", "nosniff")
+		})
+		files.Static("/", cfg.Media.Dir)
+	}
+
+	// Sitemap
+	r.GET("/sitemap.xml", sitemapHandler.GetSitemap)
+	r.GET("/sitemaps/:file", sitemapHandler.GetSitemapPage)
+
+	// Public signing keys
+	r.GET("/.well-known/jwks.json", auth.JWKS(jwtService))
+
 	// Load Templates
 	r.LoadHTMLGlob("web/templates/**/*")
 
 	// Admin Dashboard
-	dashboardHandler := dashboard.NewHandler(userService, postService)
+	dashboardHandler := dashboard.NewHandler(userService, postService, commentService)
 	admin := r.Group("/admin")
 	admin.Use(gin.BasicAuth(gin.Accounts{
 		cfg.App.AdminUser: cfg.App.AdminPassword,
@@ -114,10 +263,12 @@ func Init(cfg *config.Config) *gin.Engine {
 		admin.GET("/", dashboardHandler.ServeIndex)
 		admin.GET("/users", dashboardHandler.ServeUsers)
 		admin.GET("/posts", dashboardHandler.ServePosts)
+		admin.GET("/comments", dashboardHandler.ServeComments)
 
 		// Actions
 		admin.DELETE("/users/:id", dashboardHandler.DeleteUser)
 		admin.DELETE("/posts/:id", dashboardHandler.DeletePost)
+		admin.DELETE("/comments/:id", dashboardHandler.DeleteComment)
 	}
 
 	// 404 Handler
@@ -0,0 +1,61 @@
+package sitemap
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"post/internal/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	contentType = "application/xml; charset=utf-8"
+	maxAge      = "public, max-age=3600"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service}
+}
+
+func (h *Handler) GetSitemap(c *gin.Context) {
+	body, err := h.service.Index()
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to build sitemap", err.Error())
+		return
+	}
+
+	c.Header("Cache-Control", maxAge)
+	c.Data(http.StatusOK, contentType, body)
+}
+
+// GetSitemapPage serves one page of a split sitemap, named like
+// "posts-2.xml".
+func (h *Handler) GetSitemapPage(c *gin.Context) {
+	name, ok := strings.CutSuffix(c.Param("file"), ".xml")
+	section, number, found := strings.Cut(name, "-")
+	n, err := strconv.Atoi(number)
+	if !ok || !found || err != nil {
+		response.Error(c, http.StatusNotFound, "Sitemap not found", nil)
+		return
+	}
+
+	body, err := h.service.Page(Section(section), n)
+	if err != nil {
+		if errors.Is(err, ErrPageNotFound) {
+			response.Error(c, http.StatusNotFound, "Sitemap not found", nil)
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to build sitemap", err.Error())
+		return
+	}
+
+	c.Header("Cache-Control", maxAge)
+	c.Data(http.StatusOK, contentType, body)
+}
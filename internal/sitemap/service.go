@@ -0,0 +1,190 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"post/internal/pkg/cache"
+	"post/internal/post"
+	"post/internal/user"
+)
+
+// maxURLs is the most URLs the sitemap protocol allows in one file. Larger
+// sites are split into pages listed by a sitemap index.
+const maxURLs = 50000
+
+const namespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+var ErrPageNotFound = errors.New("sitemap page not found")
+
+// Section is a kind of page listed in the sitemap.
+type Section string
+
+const (
+	SectionPosts   Section = "posts"
+	SectionAuthors Section = "authors"
+)
+
+type Service interface {
+	// Index returns /sitemap.xml: every URL when they fit in one file,
+	// otherwise an index of the section pages.
+	Index() ([]byte, error)
+	Page(section Section, number int) ([]byte, error)
+}
+
+type service struct {
+	posts   post.Repository
+	users   user.Repository
+	cache   cache.Cache
+	baseURL string
+}
+
+// NewService returns a sitemap service linking to baseURL. Sitemaps are
+// cached under the posts prefix, so they are dropped whenever posts change.
+func NewService(posts post.Repository, users user.Repository, cache cache.Cache, baseURL string) Service {
+	return &service{posts, users, cache, baseURL}
+}
+
+type urlSet struct {
+	XMLName xml.Name `xml:"urlset"`
+	XMLNS   string   `xml:"xmlns,attr"`
+	URLs    []url    `xml:"url"`
+}
+
+type url struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name `xml:"sitemapindex"`
+	XMLNS    string   `xml:"xmlns,attr"`
+	Sitemaps []url    `xml:"sitemap"`
+}
+
+func (s *service) Index() ([]byte, error) {
+	return s.cached("posts:sitemap:index", func() ([]byte, error) {
+		postCount, err := s.posts.Count(post.Filter{})
+		if err != nil {
+			return nil, err
+		}
+		authorCount, err := s.users.CountAuthors()
+		if err != nil {
+			return nil, err
+		}
+
+		if postCount+authorCount <= maxURLs {
+			urls, err := s.postURLs(0, int(postCount))
+			if err != nil {
+				return nil, err
+			}
+			authors, err := s.authorURLs(0, int(authorCount))
+			if err != nil {
+				return nil, err
+			}
+			return marshal(urlSet{XMLNS: namespace, URLs: append(urls, authors...)})
+		}
+
+		index := sitemapIndex{XMLNS: namespace}
+		for _, section := range []struct {
+			name  Section
+			count int64
+		}{{SectionPosts, postCount}, {SectionAuthors, authorCount}} {
+			for n := 1; int64(n-1)*maxURLs < section.count; n++ {
+				index.Sitemaps = append(index.Sitemaps, url{Loc: fmt.Sprintf("%s/sitemaps/%s-%d.xml", s.baseURL, section.name, n)})
+			}
+		}
+		return marshal(index)
+	})
+}
+
+func (s *service) Page(section Section, number int) ([]byte, error) {
+	if number < 1 {
+		return nil, ErrPageNotFound
+	}
+	key := fmt.Sprintf("posts:sitemap:%s:%d", section, number)
+	return s.cached(key, func() ([]byte, error) {
+		offset := (number - 1) * maxURLs
+		var urls []url
+		var err error
+		switch section {
+		case SectionPosts:
+			urls, err = s.postURLs(offset, maxURLs)
+		case SectionAuthors:
+			urls, err = s.authorURLs(offset, maxURLs)
+		default:
+			return nil, ErrPageNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(urls) == 0 {
+			return nil, ErrPageNotFound
+		}
+		return marshal(urlSet{XMLNS: namespace, URLs: urls})
+	})
+}
+
+func (s *service) postURLs(offset, limit int) ([]url, error) {
+	if limit == 0 {
+		return nil, nil
+	}
+	links, err := s.posts.FindPublishedLinks(offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	urls := make([]url, len(links))
+	for i, link := range links {
+		urls[i] = url{Loc: s.baseURL + "/api/posts/by-slug/" + link.Slug, LastMod: lastMod(link.UpdatedAt)}
+	}
+	return urls, nil
+}
+
+func (s *service) authorURLs(offset, limit int) ([]url, error) {
+	if limit == 0 {
+		return nil, nil
+	}
+	authors, err := s.users.FindAuthors(offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	urls := make([]url, len(authors))
+	for i, author := range authors {
+		urls[i] = url{Loc: fmt.Sprintf("%s/api/users/%d/posts", s.baseURL, author.ID), LastMod: lastMod(author.UpdatedAt)}
+	}
+	return urls, nil
+}
+
+func (s *service) cached(key string, build func() ([]byte, error)) ([]byte, error) {
+	if val, ok := s.cache.Get(key); ok {
+		log.Printf("Hit Cache: %s", key)
+		return val.([]byte), nil
+	}
+
+	body, err := build()
+	if err != nil {
+		return nil, err
+	}
+
+	s.cache.Set(key, body)
+	log.Printf("Miss Cache: %s (Set)", key)
+	return body, nil
+}
+
+func lastMod(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func marshal(doc any) ([]byte, error) {
+	body, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
@@ -0,0 +1,137 @@
+package sitemap_test
+
+import (
+	"testing"
+	"time"
+
+	"post/internal/pkg/cache"
+	"post/internal/post"
+	"post/internal/sitemap"
+	"post/internal/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockPostRepository mocks the part of post.Repository sitemaps rely on.
+// Calling any other method panics.
+type MockPostRepository struct {
+	mock.Mock
+	post.Repository
+}
+
+func (m *MockPostRepository) Count(filter post.Filter) (int64, error) {
+	args := m.Called(filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockPostRepository) FindPublishedLinks(offset, limit int) ([]post.Link, error) {
+	args := m.Called(offset, limit)
+	return args.Get(0).([]post.Link), args.Error(1)
+}
+
+// MockUserRepository mocks the part of user.Repository sitemaps rely on.
+type MockUserRepository struct {
+	mock.Mock
+	user.Repository
+}
+
+func (m *MockUserRepository) FindAuthors(offset, limit int) ([]user.Author, error) {
+	args := m.Called(offset, limit)
+	return args.Get(0).([]user.Author), args.Error(1)
+}
+
+func (m *MockUserRepository) CountAuthors() (int64, error) {
+	args := m.Called()
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func newService(t *testing.T, posts *MockPostRepository, users *MockUserRepository) sitemap.Service {
+	c, err := cache.NewLRUCache(10)
+	assert.NoError(t, err)
+	return sitemap.NewService(posts, users, c, "https://example.com")
+}
+
+func TestIndex(t *testing.T) {
+	updated := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Single File", func(t *testing.T) {
+		mockPosts := new(MockPostRepository)
+		mockUsers := new(MockUserRepository)
+		service := newService(t, mockPosts, mockUsers)
+
+		mockPosts.On("Count", post.Filter{}).Return(int64(1), nil).Once()
+		mockPosts.On("FindPublishedLinks", 0, 1).Return([]post.Link{{ID: 1, Slug: "hello", UpdatedAt: updated}}, nil).Once()
+		mockUsers.On("CountAuthors").Return(int64(1), nil).Once()
+		mockUsers.On("FindAuthors", 0, 1).Return([]user.Author{{ID: 3, UpdatedAt: updated}}, nil).Once()
+
+		body, err := service.Index()
+
+		assert.NoError(t, err)
+		xml := string(body)
+		assert.Contains(t, xml, `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`)
+		assert.Contains(t, xml, "<loc>https://example.com/api/posts/by-slug/hello</loc>")
+		assert.Contains(t, xml, "<lastmod>2026-10-01T12:00:00Z</lastmod>")
+		assert.Contains(t, xml, "<loc>https://example.com/api/users/3/posts</loc>")
+
+		cached, err := service.Index()
+
+		assert.NoError(t, err)
+		assert.Equal(t, body, cached)
+		mockPosts.AssertExpectations(t)
+	})
+
+	t.Run("Split", func(t *testing.T) {
+		mockPosts := new(MockPostRepository)
+		mockUsers := new(MockUserRepository)
+		service := newService(t, mockPosts, mockUsers)
+
+		mockPosts.On("Count", post.Filter{}).Return(int64(50001), nil)
+		mockUsers.On("CountAuthors").Return(int64(20), nil)
+
+		body, err := service.Index()
+
+		assert.NoError(t, err)
+		xml := string(body)
+		assert.Contains(t, xml, "<sitemapindex")
+		assert.Contains(t, xml, "<loc>https://example.com/sitemaps/posts-1.xml</loc>")
+		assert.Contains(t, xml, "<loc>https://example.com/sitemaps/posts-2.xml</loc>")
+		assert.Contains(t, xml, "<loc>https://example.com/sitemaps/authors-1.xml</loc>")
+		assert.NotContains(t, xml, "posts-3.xml")
+		mockPosts.AssertNotCalled(t, "FindPublishedLinks", mock.Anything, mock.Anything)
+	})
+}
+
+func TestPage(t *testing.T) {
+	t.Run("Posts", func(t *testing.T) {
+		mockPosts := new(MockPostRepository)
+		service := newService(t, mockPosts, new(MockUserRepository))
+
+		mockPosts.On("FindPublishedLinks", 50000, 50000).Return([]post.Link{{ID: 50001, Slug: "last"}}, nil)
+
+		body, err := service.Page(sitemap.SectionPosts, 2)
+
+		assert.NoError(t, err)
+		assert.Contains(t, string(body), "<loc>https://example.com/api/posts/by-slug/last</loc>")
+		assert.NotContains(t, string(body), "<lastmod>")
+	})
+
+	t.Run("Past The End", func(t *testing.T) {
+		mockUsers := new(MockUserRepository)
+		service := newService(t, new(MockPostRepository), mockUsers)
+
+		mockUsers.On("FindAuthors", 50000, 50000).Return([]user.Author{}, nil)
+
+		_, err := service.Page(sitemap.SectionAuthors, 2)
+
+		assert.ErrorIs(t, err, sitemap.ErrPageNotFound)
+	})
+
+	t.Run("Unknown Section", func(t *testing.T) {
+		service := newService(t, new(MockPostRepository), new(MockUserRepository))
+
+		_, err := service.Page("tags", 1)
+
+		assert.ErrorIs(t, err, sitemap.ErrPageNotFound)
+	})
+}
@@ -0,0 +1,60 @@
+package syndication
+
+import (
+	"fmt"
+	"time"
+)
+
+// Format is one of the syndication formats a feed can be rendered in.
+type Format string
+
+const (
+	FormatRSS  Format = "rss"
+	FormatAtom Format = "atom"
+	FormatJSON Format = "json"
+)
+
+var contentTypes = map[Format]string{
+	FormatRSS:  "application/rss+xml; charset=utf-8",
+	FormatAtom: "application/atom+xml; charset=utf-8",
+	FormatJSON: "application/feed+json; charset=utf-8",
+}
+
+// Feed is the format independent content of a feed.
+type Feed struct {
+	Title       string
+	Description string
+	// Link is the page the feed mirrors and FeedURL where the feed itself
+	// is served.
+	Link    string
+	FeedURL string
+	Updated time.Time
+	Items   []Item
+}
+
+// Item is a single post in a feed.
+type Item struct {
+	ID        string
+	Title     string
+	Link      string
+	Summary   string
+	Content   string
+	Author    string
+	Tags      []string
+	Published time.Time
+	Updated   time.Time
+}
+
+// render encodes the feed in the given format.
+func (f *Feed) render(format Format) ([]byte, error) {
+	switch format {
+	case FormatRSS:
+		return f.rss()
+	case FormatAtom:
+		return f.atom()
+	case FormatJSON:
+		return f.json()
+	default:
+		return nil, fmt.Errorf("unknown feed format %q", format)
+	}
+}
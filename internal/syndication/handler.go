@@ -0,0 +1,70 @@
+package syndication
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	pkgdb "post/internal/pkg/database"
+	"post/internal/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxAge is how long clients and proxies may reuse a feed without asking.
+const maxAge = "public, max-age=300"
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service}
+}
+
+// GetPostsFeed serves the feed of every published post.
+func (h *Handler) GetPostsFeed(c *gin.Context) {
+	h.serve(c, Scope{})
+}
+
+func (h *Handler) GetAuthorFeed(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid ID", err.Error())
+		return
+	}
+	h.serve(c, Scope{AuthorID: uint(id)})
+}
+
+func (h *Handler) GetTagFeed(c *gin.Context) {
+	tag := strings.ToLower(strings.TrimSpace(c.Param("tag")))
+	if tag == "" {
+		response.Error(c, http.StatusBadRequest, "Invalid tag", nil)
+		return
+	}
+	h.serve(c, Scope{Tag: tag})
+}
+
+// serve writes the feed in the format named by the route's extension.
+// http.ServeContent answers If-None-Match and If-Modified-Since with a 304.
+func (h *Handler) serve(c *gin.Context, scope Scope) {
+	format := Format(strings.TrimPrefix(path.Ext(c.FullPath()), "."))
+
+	doc, err := h.service.Get(scope, format)
+	if err != nil {
+		if errors.Is(err, pkgdb.ErrRecordNotFound) {
+			response.Error(c, http.StatusNotFound, "User not found", nil)
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to build feed", err.Error())
+		return
+	}
+
+	c.Header("Content-Type", doc.ContentType)
+	c.Header("ETag", doc.ETag)
+	c.Header("Cache-Control", maxAge)
+	http.ServeContent(c.Writer, c.Request, "", doc.LastModified, bytes.NewReader(doc.Body))
+}
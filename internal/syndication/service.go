@@ -0,0 +1,178 @@
+package syndication
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"time"
+
+	"post/internal/entity"
+	"post/internal/pkg/cache"
+	pkgdb "post/internal/pkg/database"
+	"post/internal/post"
+	"post/internal/profile"
+	"post/internal/user"
+)
+
+// feedSize is how many of the latest posts a feed lists.
+const feedSize = 20
+
+type Service interface {
+	Get(scope Scope, format Format) (*Document, error)
+}
+
+// Scope selects the posts of a feed: every published post, or those of a
+// single author or tag.
+type Scope struct {
+	AuthorID uint
+	Tag      string
+}
+
+// path is where the feed of the scope is served, without the format extension.
+func (s Scope) path() string {
+	switch {
+	case s.AuthorID != 0:
+		return "/feeds/users/" + strconv.FormatUint(uint64(s.AuthorID), 10) + "/posts"
+	case s.Tag != "":
+		return "/feeds/tags/" + url.PathEscape(s.Tag) + "/posts"
+	default:
+		return "/feeds/posts"
+	}
+}
+
+// Document is a rendered feed with the validators used for conditional
+// requests.
+type Document struct {
+	Body         []byte
+	ContentType  string
+	ETag         string
+	LastModified time.Time
+}
+
+type service struct {
+	posts    post.Service
+	users    user.Service
+	profiles profile.Service
+	cache    cache.Cache
+	siteName string
+	baseURL  string
+}
+
+// NewService returns a feed service linking to baseURL. Rendered feeds are
+// cached under the posts prefix, so they are dropped whenever posts or
+// profiles change.
+func NewService(posts post.Service, users user.Service, profiles profile.Service, cache cache.Cache, siteName, baseURL string) Service {
+	return &service{posts, users, profiles, cache, siteName, baseURL}
+}
+
+func (s *service) Get(scope Scope, format Format) (*Document, error) {
+	key := fmt.Sprintf("posts:feeds:%s.%s", scope.path(), format)
+	if val, ok := s.cache.Get(key); ok {
+		log.Printf("Hit Cache: %s", key)
+		return val.(*Document), nil
+	}
+
+	// The entry is dropped whenever a post or profile changes, so the time
+	// it is built covers every change in scope. Taking it from the newest
+	// item instead would move backwards once that item is deleted or
+	// unpublished, and clients would keep a stale copy.
+	builtAt := time.Now()
+	feed, err := s.build(scope, format)
+	if err != nil {
+		return nil, err
+	}
+	body, err := feed.render(format)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(body)
+	doc := &Document{
+		Body:         body,
+		ContentType:  contentTypes[format],
+		ETag:         `"` + hex.EncodeToString(sum[:16]) + `"`,
+		LastModified: builtAt,
+	}
+
+	s.cache.Set(key, doc)
+	log.Printf("Miss Cache: %s (Set)", key)
+	return doc, nil
+}
+
+// build collects the latest published posts of the scope.
+func (s *service) build(scope Scope, format Format) (*Feed, error) {
+	baseURL := s.baseURL
+	feed := &Feed{
+		Title:       s.siteName,
+		Description: "Latest posts on " + s.siteName,
+		Link:        baseURL + "/api/posts",
+		FeedURL:     baseURL + scope.path() + "." + string(format),
+	}
+	switch {
+	case scope.AuthorID != 0:
+		if _, err := s.users.GetByID(scope.AuthorID); err != nil {
+			return nil, pkgdb.ParseError(err)
+		}
+		name := s.authorName(scope.AuthorID)
+		feed.Title = s.siteName + ": " + name
+		feed.Description = "Latest posts by " + name + " on " + s.siteName
+		feed.Link = fmt.Sprintf("%s/api/posts?author_id=%d", baseURL, scope.AuthorID)
+	case scope.Tag != "":
+		feed.Title = s.siteName + ": #" + scope.Tag
+		feed.Description = "Latest posts tagged " + scope.Tag + " on " + s.siteName
+		feed.Link = baseURL + "/api/posts?tag=" + url.QueryEscape(scope.Tag)
+	}
+
+	page, err := s.posts.List(post.Viewer{}, post.ListQuery{Limit: feedSize, Tag: scope.Tag, Author: scope.AuthorID})
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[uint]string)
+	for _, p := range page.Posts {
+		if _, ok := names[p.UserID]; !ok {
+			names[p.UserID] = s.authorName(p.UserID)
+		}
+		feed.Items = append(feed.Items, itemOf(baseURL, p, names[p.UserID]))
+		if p.UpdatedAt.After(feed.Updated) {
+			feed.Updated = p.UpdatedAt
+		}
+	}
+	if feed.Updated.IsZero() {
+		feed.Updated = time.Now()
+	}
+	return feed, nil
+}
+
+// authorName is the author's profile name, or a generic one for users
+// without a profile. Emails are never put in feeds.
+func (s *service) authorName(userID uint) string {
+	if profile, err := s.profiles.GetByUserID(userID); err == nil && profile.Name != "" {
+		return profile.Name
+	}
+	return fmt.Sprintf("User %d", userID)
+}
+
+func itemOf(baseURL string, p entity.Post, author string) Item {
+	published := p.CreatedAt
+	if p.PublishedAt != nil {
+		published = *p.PublishedAt
+	}
+	tags := make([]string, len(p.Tags))
+	for i, tag := range p.Tags {
+		tags[i] = tag.Name
+	}
+	return Item{
+		ID:        fmt.Sprintf("%s/api/posts/%d", baseURL, p.ID),
+		Title:     p.Title,
+		Link:      baseURL + "/api/posts/by-slug/" + p.Slug,
+		Summary:   p.Excerpt,
+		Content:   p.ContentHTML,
+		Author:    author,
+		Tags:      tags,
+		Published: published,
+		Updated:   p.UpdatedAt,
+	}
+}
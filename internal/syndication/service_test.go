@@ -0,0 +1,174 @@
+package syndication_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"post/internal/entity"
+	"post/internal/pkg/cache"
+	pkgdb "post/internal/pkg/database"
+	"post/internal/post"
+	"post/internal/profile"
+	"post/internal/syndication"
+	"post/internal/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// MockPostService mocks the part of post.Service feeds rely on. Calling any
+// other method panics.
+type MockPostService struct {
+	mock.Mock
+	post.Service
+}
+
+func (m *MockPostService) List(viewer post.Viewer, query post.ListQuery) (*post.PostPage, error) {
+	args := m.Called(viewer, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*post.PostPage), args.Error(1)
+}
+
+type MockUserService struct {
+	mock.Mock
+	user.Service
+}
+
+func (m *MockUserService) GetByID(id uint) (*entity.User, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.User), args.Error(1)
+}
+
+type MockProfileService struct {
+	mock.Mock
+	profile.Service
+}
+
+func (m *MockProfileService) GetByUserID(userID uint) (*entity.Profile, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Profile), args.Error(1)
+}
+
+func newCache(t *testing.T) cache.Cache {
+	c, err := cache.NewLRUCache(10)
+	assert.NoError(t, err)
+	return c
+}
+
+func TestGet(t *testing.T) {
+	updated := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	page := &post.PostPage{Posts: []entity.Post{{
+		ID:          7,
+		UserID:      2,
+		Title:       "Hello & welcome",
+		Slug:        "hello-welcome",
+		ContentHTML: "<p>Hi <em>there</em></p>",
+		Excerpt:     "Hi there",
+		Tags:        []entity.Tag{{Name: "go"}},
+		CreatedAt:   updated.Add(-time.Hour),
+		UpdatedAt:   updated,
+	}}}
+
+	t.Run("RSS Cached", func(t *testing.T) {
+		mockPosts := new(MockPostService)
+		mockProfiles := new(MockProfileService)
+		service := syndication.NewService(mockPosts, new(MockUserService), mockProfiles, newCache(t), "blog", "https://example.com")
+
+		mockPosts.On("List", post.Viewer{}, post.ListQuery{Limit: 20}).Return(page, nil).Once()
+		mockProfiles.On("GetByUserID", uint(2)).Return(&entity.Profile{Name: "Ada"}, nil)
+
+		before := time.Now()
+		doc, err := service.Get(syndication.Scope{}, syndication.FormatRSS)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "application/rss+xml; charset=utf-8", doc.ContentType)
+		assert.False(t, doc.LastModified.Before(before))
+		body := string(doc.Body)
+		assert.Contains(t, body, "<title>Hello &amp; welcome</title>")
+		assert.Contains(t, body, "<link>https://example.com/api/posts/by-slug/hello-welcome</link>")
+		assert.Contains(t, body, "&lt;p&gt;Hi &lt;em&gt;there&lt;/em&gt;&lt;/p&gt;")
+		assert.Contains(t, body, "<dc:creator>Ada</dc:creator>")
+		assert.Contains(t, body, `<atom:link href="https://example.com/feeds/posts.rss" rel="self"`)
+
+		again, err := service.Get(syndication.Scope{}, syndication.FormatRSS)
+
+		assert.NoError(t, err)
+		assert.Equal(t, doc.ETag, again.ETag)
+		assert.Equal(t, doc.LastModified, again.LastModified)
+		mockPosts.AssertExpectations(t)
+	})
+
+	t.Run("Newest Post Removed", func(t *testing.T) {
+		mockPosts := new(MockPostService)
+		mockProfiles := new(MockProfileService)
+		feeds := newCache(t)
+		service := syndication.NewService(mockPosts, new(MockUserService), mockProfiles, feeds, "blog", "https://example.com")
+
+		older := *page
+		older.Posts = []entity.Post{page.Posts[0]}
+		older.Posts[0].UpdatedAt = updated.Add(-24 * time.Hour)
+		mockPosts.On("List", post.Viewer{}, post.ListQuery{Limit: 20}).Return(page, nil).Once()
+		mockPosts.On("List", post.Viewer{}, post.ListQuery{Limit: 20}).Return(&older, nil).Once()
+		mockProfiles.On("GetByUserID", uint(2)).Return(&entity.Profile{Name: "Ada"}, nil)
+
+		first, err := service.Get(syndication.Scope{}, syndication.FormatRSS)
+		assert.NoError(t, err)
+		feeds.DeletePrefix("posts:")
+		second, err := service.Get(syndication.Scope{}, syndication.FormatRSS)
+
+		assert.NoError(t, err)
+		assert.False(t, second.LastModified.Before(first.LastModified))
+		assert.NotEqual(t, first.ETag, second.ETag)
+	})
+
+	t.Run("JSON Feed", func(t *testing.T) {
+		mockPosts := new(MockPostService)
+		mockProfiles := new(MockProfileService)
+		service := syndication.NewService(mockPosts, new(MockUserService), mockProfiles, newCache(t), "blog", "https://example.com")
+
+		mockPosts.On("List", post.Viewer{}, post.ListQuery{Limit: 20, Tag: "go"}).Return(page, nil)
+		mockProfiles.On("GetByUserID", uint(2)).Return(nil, gorm.ErrRecordNotFound)
+
+		doc, err := service.Get(syndication.Scope{Tag: "go"}, syndication.FormatJSON)
+
+		assert.NoError(t, err)
+		var feed struct {
+			Version string `json:"version"`
+			FeedURL string `json:"feed_url"`
+			Items   []struct {
+				ID      string `json:"id"`
+				Authors []struct {
+					Name string `json:"name"`
+				} `json:"authors"`
+			} `json:"items"`
+		}
+		assert.NoError(t, json.Unmarshal(doc.Body, &feed))
+		assert.Equal(t, "https://jsonfeed.org/version/1.1", feed.Version)
+		assert.Equal(t, "https://example.com/feeds/tags/go/posts.json", feed.FeedURL)
+		assert.Equal(t, "https://example.com/api/posts/7", feed.Items[0].ID)
+		assert.Equal(t, "User 2", feed.Items[0].Authors[0].Name)
+	})
+
+	t.Run("Unknown Author", func(t *testing.T) {
+		mockPosts := new(MockPostService)
+		mockUsers := new(MockUserService)
+		service := syndication.NewService(mockPosts, mockUsers, new(MockProfileService), newCache(t), "blog", "https://example.com")
+
+		mockUsers.On("GetByID", uint(9)).Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := service.Get(syndication.Scope{AuthorID: 9}, syndication.FormatAtom)
+
+		assert.ErrorIs(t, err, pkgdb.ErrRecordNotFound)
+		mockPosts.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+	})
+}
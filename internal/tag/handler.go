@@ -0,0 +1,27 @@
+package tag
+
+import (
+	"net/http"
+
+	"post/internal/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service}
+}
+
+func (h *Handler) GetAllTags(c *gin.Context) {
+	tags, err := h.service.GetAll()
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to fetch tags", err.Error())
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Tags retrieved", tags)
+}
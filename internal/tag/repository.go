@@ -0,0 +1,39 @@
+package tag
+
+import (
+	"post/internal/entity"
+
+	"gorm.io/gorm"
+)
+
+// TagCount is a tag with the number of published posts using it.
+type TagCount struct {
+	ID        uint   `json:"id"`
+	Name      string `json:"name"`
+	PostCount int64  `json:"post_count"`
+}
+
+type Repository interface {
+	FindAllWithCounts() ([]TagCount, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db}
+}
+
+func (r *repository) FindAllWithCounts() ([]TagCount, error) {
+	var tags []TagCount
+	err := r.db.Table("tags").
+		Select("tags.id, tags.name, COUNT(users.id) AS post_count").
+		Joins("LEFT JOIN post_tags ON post_tags.tag_id = tags.id").
+		Joins("LEFT JOIN posts ON posts.id = post_tags.post_id AND posts.deleted_at IS NULL AND posts.status = ?", entity.PostStatusPublished).
+		Joins("LEFT JOIN users ON users.id = posts.user_id AND users.deleted_at IS NULL").
+		Group("tags.id, tags.name").
+		Order("post_count DESC, tags.name").
+		Scan(&tags).Error
+	return tags, err
+}
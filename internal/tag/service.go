@@ -0,0 +1,17 @@
+package tag
+
+type Service interface {
+	GetAll() ([]TagCount, error)
+}
+
+type service struct {
+	repo Repository
+}
+
+func NewService(repo Repository) Service {
+	return &service{repo}
+}
+
+func (s *service) GetAll() ([]TagCount, error) {
+	return s.repo.FindAllWithCounts()
+}
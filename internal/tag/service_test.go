@@ -0,0 +1,56 @@
+package tag_test
+
+import (
+	"errors"
+	"testing"
+
+	"post/internal/tag"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockRepository is a mock of tag.Repository
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) FindAllWithCounts() ([]tag.TagCount, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]tag.TagCount), args.Error(1)
+}
+
+func TestGetAll(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := tag.NewService(mockRepo)
+		expected := []tag.TagCount{
+			{ID: 1, Name: "go", PostCount: 3},
+			{ID: 2, Name: "web", PostCount: 1},
+		}
+
+		mockRepo.On("FindAllWithCounts").Return(expected, nil)
+
+		result, err := service.GetAll()
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, result)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Failure", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := tag.NewService(mockRepo)
+
+		mockRepo.On("FindAllWithCounts").Return(nil, errors.New("db error"))
+
+		result, err := service.GetAll()
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		mockRepo.AssertExpectations(t)
+	})
+}
@@ -1,9 +1,12 @@
 package user
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
+	"post/internal/entity"
+	pkgdb "post/internal/pkg/database"
 	"post/internal/pkg/response"
 
 	"github.com/gin-gonic/gin"
@@ -27,9 +30,11 @@ func (h *Handler) GetProfile(c *gin.Context) {
 		return
 	}
 
-	response.Success(c, http.StatusOK, "User profile retrieved", user)
+	response.Success(c, http.StatusOK, "User profile retrieved", NewSelfUser(user))
 }
 
+// GetUserByID shows the user as the caller may see them. Only roles that may
+// read emails and the user themselves see the email and role.
 func (h *Handler) GetUserByID(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
@@ -44,5 +49,67 @@ func (h *Handler) GetUserByID(c *gin.Context) {
 		return
 	}
 
-	response.Success(c, http.StatusOK, "User retrieved", user)
+	role, _ := c.Get("role")
+	viewerRole, _ := role.(entity.Role)
+	response.Success(c, http.StatusOK, "User retrieved", View(user, c.GetUint("userID"), viewerRole))
+}
+
+type RoleInput struct {
+	Role entity.Role `json:"role" binding:"required"`
+}
+
+// SetRole changes a user's role. The route requires the users:update:role
+// permission.
+func (h *Handler) SetRole(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid ID", err.Error())
+		return
+	}
+
+	var input RoleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+
+	user, err := h.service.SetRole(uint(id), c.GetUint("userID"), input.Role)
+	if err != nil {
+		writeError(c, err, "Failed to update role")
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Role updated", NewAdminUser(user))
+}
+
+// RoleView is a role and the permissions it grants.
+type RoleView struct {
+	ID          entity.Role         `json:"id"`
+	Name        string              `json:"name"`
+	Permissions []entity.Permission `json:"permissions"`
+}
+
+// GetRoles lists the roles that can be assigned.
+func (h *Handler) GetRoles(c *gin.Context) {
+	roles := make([]RoleView, len(entity.Roles))
+	for i, role := range entity.Roles {
+		roles[i] = RoleView{ID: role, Name: role.String(), Permissions: role.Permissions()}
+		if roles[i].Permissions == nil {
+			roles[i].Permissions = []entity.Permission{}
+		}
+	}
+	response.Success(c, http.StatusOK, "Roles retrieved", roles)
+}
+
+func writeError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, pkgdb.ErrRecordNotFound):
+		response.Error(c, http.StatusNotFound, "User not found", nil)
+	case errors.Is(err, ErrInvalidRole):
+		response.Error(c, http.StatusBadRequest, "Invalid input", err.Error())
+	case errors.Is(err, ErrOwnRole):
+		response.Error(c, http.StatusForbidden, "Forbidden", err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, message, err.Error())
+	}
 }
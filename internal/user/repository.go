@@ -1,6 +1,8 @@
 package user
 
 import (
+	"time"
+
 	"post/internal/entity"
 
 	"gorm.io/gorm"
@@ -11,9 +13,19 @@ type Repository interface {
 	FindByEmail(email string) (*entity.User, error)
 	FindByID(id uint) (*entity.User, error)
 	FindAll() ([]entity.User, error)
+	FindAuthors(offset, limit int) ([]Author, error)
+	CountAuthors() (int64, error)
+	UpdateRole(id uint, role entity.Role) error
 	Delete(id uint) error
 }
 
+// Author is a user with published posts. UpdatedAt is the latest change to
+// the user or any of those posts.
+type Author struct {
+	ID        uint
+	UpdatedAt time.Time
+}
+
 type repository struct {
 	db *gorm.DB
 }
@@ -32,9 +44,17 @@ func (r *repository) FindByEmail(email string) (*entity.User, error) {
 	return &user, err
 }
 
+// followCounts selects how many live users follow, and are followed by, each
+// user row.
+const followCounts = `users.*,
+	(SELECT COUNT(*) FROM follows JOIN users f ON f.id = follows.follower_id AND f.deleted_at IS NULL WHERE follows.followee_id = users.id) AS followers_count,
+	(SELECT COUNT(*) FROM follows JOIN users f ON f.id = follows.followee_id AND f.deleted_at IS NULL WHERE follows.follower_id = users.id) AS following_count`
+
+// FindByID returns the user along with their profile and their follower and
+// following counts.
 func (r *repository) FindByID(id uint) (*entity.User, error) {
 	var user entity.User
-	err := r.db.First(&user, id).Error
+	err := r.db.Select(followCounts).Preload("Profile").First(&user, id).Error
 	return &user, err
 }
 
@@ -44,6 +64,32 @@ func (r *repository) FindAll() ([]entity.User, error) {
 	return users, err
 }
 
+// FindAuthors pages through authors in ID order.
+func (r *repository) FindAuthors(offset, limit int) ([]Author, error) {
+	var authors []Author
+	err := r.db.Model(&entity.User{}).
+		Select("users.id, GREATEST(users.updated_at, MAX(posts.updated_at)) AS updated_at").
+		Joins("JOIN posts ON posts.user_id = users.id AND posts.status = ? AND posts.deleted_at IS NULL", entity.PostStatusPublished).
+		Group("users.id").
+		Order("users.id").
+		Offset(offset).
+		Limit(limit).
+		Scan(&authors).Error
+	return authors, err
+}
+
+func (r *repository) CountAuthors() (int64, error) {
+	var total int64
+	err := r.db.Model(&entity.User{}).
+		Where("EXISTS (SELECT 1 FROM posts WHERE posts.user_id = users.id AND posts.status = ? AND posts.deleted_at IS NULL)", entity.PostStatusPublished).
+		Count(&total).Error
+	return total, err
+}
+
+func (r *repository) UpdateRole(id uint, role entity.Role) error {
+	return r.db.Model(&entity.User{}).Where("id = ?", id).Update("role", role).Error
+}
+
 func (r *repository) Delete(id uint) error {
 	return r.db.Delete(&entity.User{}, id).Error
 }
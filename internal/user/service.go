@@ -1,22 +1,38 @@
 package user
 
 import (
+	"errors"
+
 	"post/internal/entity"
+	pkgdb "post/internal/pkg/database"
+)
+
+var (
+	ErrInvalidRole = errors.New("unknown role")
+	ErrOwnRole     = errors.New("you cannot change your own role")
 )
 
 type Service interface {
 	GetByID(id uint) (*entity.User, error)
 	GetByEmail(email string) (*entity.User, error)
 	GetAll() ([]entity.User, error)
+	SetRole(id, actorID uint, role entity.Role) (*entity.User, error)
 	Delete(id uint) error
 }
 
+// Revoker signs a user out on every device. Tokens carry the user's role,
+// so they have to go when the role changes or the user is deleted.
+type Revoker interface {
+	RevokeUser(userID uint) error
+}
+
 type service struct {
-	repo Repository
+	repo    Repository
+	revoker Revoker
 }
 
-func NewService(repo Repository) Service {
-	return &service{repo}
+func NewService(repo Repository, revoker Revoker) Service {
+	return &service{repo, revoker}
 }
 
 func (s *service) GetByID(id uint) (*entity.User, error) {
@@ -31,6 +47,39 @@ func (s *service) GetAll() ([]entity.User, error) {
 	return s.repo.FindAll()
 }
 
+// SetRole gives the user a new role. Admins cannot change their own role, so
+// the last admin can never demote themselves by accident. The user is signed
+// out everywhere, so no token keeps the old role.
+func (s *service) SetRole(id, actorID uint, role entity.Role) (*entity.User, error) {
+	if !role.Valid() {
+		return nil, ErrInvalidRole
+	}
+	if id == actorID {
+		return nil, ErrOwnRole
+	}
+
+	user, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, pkgdb.ParseError(err)
+	}
+	if user.Role == role {
+		return user, nil
+	}
+
+	if err := s.repo.UpdateRole(id, role); err != nil {
+		return nil, pkgdb.ParseError(err)
+	}
+	if err := s.revoker.RevokeUser(id); err != nil {
+		return nil, err
+	}
+	user.Role = role
+	return user, nil
+}
+
+// Delete removes the user and revokes every token they hold.
 func (s *service) Delete(id uint) error {
-	return s.repo.Delete(id)
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	return s.revoker.RevokeUser(id)
 }
@@ -45,14 +45,39 @@ func (m *MockRepository) FindAll() ([]entity.User, error) {
 	return args.Get(0).([]entity.User), args.Error(1)
 }
 
+func (m *MockRepository) FindAuthors(offset, limit int) ([]user.Author, error) {
+	args := m.Called(offset, limit)
+	return args.Get(0).([]user.Author), args.Error(1)
+}
+
+func (m *MockRepository) CountAuthors() (int64, error) {
+	args := m.Called()
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRepository) UpdateRole(id uint, role entity.Role) error {
+	args := m.Called(id, role)
+	return args.Error(0)
+}
+
 func (m *MockRepository) Delete(id uint) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
 
+// MockRevoker is a mock of user.Revoker
+type MockRevoker struct {
+	mock.Mock
+}
+
+func (m *MockRevoker) RevokeUser(userID uint) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
 func TestGetByID(t *testing.T) {
 	mockRepo := new(MockRepository)
-	service := user.NewService(mockRepo)
+	service := user.NewService(mockRepo, new(MockRevoker))
 
 	t.Run("Success", func(t *testing.T) {
 		expectedUser := &entity.User{ID: 1, Email: "test@example.com"}
@@ -78,7 +103,7 @@ func TestGetByID(t *testing.T) {
 
 func TestGetByEmail(t *testing.T) {
 	mockRepo := new(MockRepository)
-	service := user.NewService(mockRepo)
+	service := user.NewService(mockRepo, new(MockRevoker))
 
 	t.Run("Success", func(t *testing.T) {
 		expectedUser := &entity.User{ID: 1, Email: "test@example.com"}
@@ -91,3 +116,115 @@ func TestGetByEmail(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 }
+
+func TestView(t *testing.T) {
+	u := &entity.User{ID: 1, Email: "test@example.com", Role: entity.RoleUser}
+
+	t.Run("Public", func(t *testing.T) {
+		view := user.View(u, 0, 0)
+
+		assert.IsType(t, user.PublicUser{}, view)
+	})
+
+	t.Run("OtherUser", func(t *testing.T) {
+		view := user.View(u, 2, entity.RoleUser)
+
+		assert.IsType(t, user.PublicUser{}, view)
+	})
+
+	t.Run("Self", func(t *testing.T) {
+		view := user.View(u, 1, entity.RoleUser)
+
+		assert.IsType(t, user.SelfUser{}, view)
+		assert.Equal(t, "test@example.com", view.(user.SelfUser).Email)
+	})
+
+	t.Run("Admin", func(t *testing.T) {
+		view := user.View(u, 2, entity.RoleAdmin)
+
+		assert.IsType(t, user.AdminUser{}, view)
+	})
+
+	t.Run("Editor", func(t *testing.T) {
+		view := user.View(u, 2, entity.RoleEditor)
+
+		assert.IsType(t, user.PublicUser{}, view)
+	})
+
+	t.Run("NotLoaded", func(t *testing.T) {
+		assert.Nil(t, user.View(&entity.User{}, 1, entity.RoleAdmin))
+	})
+}
+
+func TestSetRole(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockRevoker := new(MockRevoker)
+	service := user.NewService(mockRepo, mockRevoker)
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo.On("FindByID", uint(3)).Return(&entity.User{ID: 3, Role: entity.RoleUser}, nil).Once()
+		mockRepo.On("UpdateRole", uint(3), entity.RoleEditor).Return(nil).Once()
+		mockRevoker.On("RevokeUser", uint(3)).Return(nil).Once()
+
+		result, err := service.SetRole(3, 1, entity.RoleEditor)
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.RoleEditor, result.Role)
+		mockRepo.AssertExpectations(t)
+		mockRevoker.AssertExpectations(t)
+	})
+
+	t.Run("SameRole", func(t *testing.T) {
+		mockRepo.On("FindByID", uint(4)).Return(&entity.User{ID: 4, Role: entity.RoleEditor}, nil).Once()
+
+		_, err := service.SetRole(4, 1, entity.RoleEditor)
+
+		assert.NoError(t, err)
+		mockRevoker.AssertNotCalled(t, "RevokeUser", uint(4))
+	})
+
+	t.Run("InvalidRole", func(t *testing.T) {
+		_, err := service.SetRole(3, 1, entity.Role(42))
+
+		assert.ErrorIs(t, err, user.ErrInvalidRole)
+	})
+
+	t.Run("OwnRole", func(t *testing.T) {
+		_, err := service.SetRole(1, 1, entity.RoleUser)
+
+		assert.ErrorIs(t, err, user.ErrOwnRole)
+		mockRepo.AssertNotCalled(t, "UpdateRole", uint(1), entity.RoleUser)
+	})
+}
+
+func TestDelete(t *testing.T) {
+	t.Run("RevokesTokens", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRevoker := new(MockRevoker)
+		service := user.NewService(mockRepo, mockRevoker)
+
+		mockRepo.On("Delete", uint(3)).Return(nil)
+		mockRevoker.On("RevokeUser", uint(3)).Return(nil)
+
+		assert.NoError(t, service.Delete(3))
+		mockRevoker.AssertExpectations(t)
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRevoker := new(MockRevoker)
+		service := user.NewService(mockRepo, mockRevoker)
+
+		mockRepo.On("Delete", uint(3)).Return(errors.New("db down"))
+
+		assert.Error(t, service.Delete(3))
+		mockRevoker.AssertNotCalled(t, "RevokeUser", uint(3))
+	})
+}
+
+func TestRolePermissions(t *testing.T) {
+	assert.True(t, entity.RoleAdmin.Can(entity.PermUsersUpdateRole))
+	assert.True(t, entity.RoleEditor.Can(entity.PermPostsDeleteAny))
+	assert.False(t, entity.RoleEditor.Can(entity.PermUsersReadEmail))
+	assert.False(t, entity.RoleUser.Can(entity.PermPostsReadAny))
+}
@@ -0,0 +1,89 @@
+package user
+
+import (
+	"time"
+
+	"post/internal/entity"
+)
+
+// PublicUser is what anyone may see of a user. The handle, name and avatar
+// come from the profile, which must be loaded for them to be set.
+type PublicUser struct {
+	ID             uint      `json:"id"`
+	Handle         *string   `json:"handle"`
+	Name           string    `json:"name"`
+	AvatarURL      string    `json:"avatar_url"`
+	FollowersCount *int64    `json:"followers_count,omitempty"`
+	FollowingCount *int64    `json:"following_count,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// SelfUser is how users see their own account.
+type SelfUser struct {
+	PublicUser
+	Email     string      `json:"email"`
+	Role      entity.Role `json:"role"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// AdminUser is how admins see any account, deleted ones included.
+type AdminUser struct {
+	SelfUser
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+func NewPublicUser(u *entity.User) PublicUser {
+	return PublicUser{
+		ID:             u.ID,
+		Handle:         u.Profile.Handle,
+		Name:           u.Profile.Name,
+		AvatarURL:      u.Profile.AvatarURL,
+		FollowersCount: u.FollowersCount,
+		FollowingCount: u.FollowingCount,
+		CreatedAt:      u.CreatedAt,
+	}
+}
+
+func NewSelfUser(u *entity.User) SelfUser {
+	return SelfUser{
+		PublicUser: NewPublicUser(u),
+		Email:      u.Email,
+		Role:       u.Role,
+		UpdatedAt:  u.UpdatedAt,
+	}
+}
+
+func NewAdminUser(u *entity.User) AdminUser {
+	admin := AdminUser{SelfUser: NewSelfUser(u)}
+	if u.DeletedAt.Valid {
+		admin.DeletedAt = &u.DeletedAt.Time
+	}
+	return admin
+}
+
+// View returns the representation of u the viewer may see: roles that may
+// read emails get the admin view, users their own self view and everyone else
+// the public view.
+// It returns nil for a user that was not loaded, so it drops out of JSON
+// fields tagged omitempty.
+func View(u *entity.User, viewerID uint, viewerRole entity.Role) any {
+	switch {
+	case u.ID == 0:
+		return nil
+	case viewerID != 0 && viewerRole.Can(entity.PermUsersReadEmail):
+		return NewAdminUser(u)
+	case viewerID != 0 && viewerID == u.ID:
+		return NewSelfUser(u)
+	default:
+		return NewPublicUser(u)
+	}
+}
+
+// Views returns the representation of each user the viewer may see.
+func Views(users []entity.User, viewerID uint, viewerRole entity.Role) []any {
+	views := make([]any, len(users))
+	for i := range users {
+		views[i] = View(&users[i], viewerID, viewerRole)
+	}
+	return views
+}
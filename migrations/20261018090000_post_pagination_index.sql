@@ -0,0 +1,2 @@
+-- Create index "idx_posts_created_at_id" to table: "posts"
+CREATE INDEX "idx_posts_created_at_id" ON "public"."posts" ("created_at", "id");
@@ -0,0 +1,4 @@
+-- Modify "posts" table
+ALTER TABLE "public"."posts" ADD COLUMN "search_vector" tsvector NULL GENERATED ALWAYS AS ((setweight(to_tsvector('english'::regconfig, COALESCE(title, ''::text)), 'A'::"char") || setweight(to_tsvector('english'::regconfig, COALESCE(content, ''::text)), 'B'::"char"))) STORED;
+-- Create index "idx_posts_search_vector" to table: "posts"
+CREATE INDEX "idx_posts_search_vector" ON "public"."posts" USING gin ("search_vector");
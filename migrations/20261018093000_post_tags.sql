@@ -0,0 +1,16 @@
+-- Create "post_tags" table
+CREATE TABLE "public"."post_tags" (
+  "tag_id" bigint NOT NULL,
+  "post_id" bigint NOT NULL,
+  PRIMARY KEY ("tag_id", "post_id")
+);
+-- Create "tags" table
+CREATE TABLE "public"."tags" (
+  "id" bigserial NOT NULL,
+  "created_at" timestamptz NULL,
+  "updated_at" timestamptz NULL,
+  "name" text NOT NULL,
+  PRIMARY KEY ("id")
+);
+-- Create index "idx_tags_name" to table: "tags"
+CREATE UNIQUE INDEX "idx_tags_name" ON "public"."tags" ("name");
@@ -0,0 +1,8 @@
+-- Modify "posts" table
+ALTER TABLE "public"."posts" ADD COLUMN "status" text NOT NULL DEFAULT 'published', ADD COLUMN "published_at" timestamptz NULL;
+-- Posts created before the lifecycle existed were public, keep them that way
+UPDATE "public"."posts" SET "published_at" = "created_at";
+-- New posts start as drafts
+ALTER TABLE "public"."posts" ALTER COLUMN "status" SET DEFAULT 'draft';
+-- Create index "idx_posts_status" to table: "posts"
+CREATE INDEX "idx_posts_status" ON "public"."posts" ("status");
@@ -0,0 +1,4 @@
+-- Modify "posts" table
+ALTER TABLE "public"."posts" ADD COLUMN "publish_at" timestamptz NULL;
+-- Create index "idx_posts_publish_at" to table: "posts"
+CREATE INDEX "idx_posts_publish_at" ON "public"."posts" ("publish_at");
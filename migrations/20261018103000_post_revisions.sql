@@ -0,0 +1,16 @@
+-- Create "post_revisions" table
+CREATE TABLE "public"."post_revisions" (
+  "id" bigserial NOT NULL,
+  "created_at" timestamptz NULL,
+  "post_id" bigint NOT NULL,
+  "number" bigint NOT NULL,
+  "title" text NOT NULL,
+  "content" text NOT NULL,
+  "editor_id" bigint NOT NULL,
+  PRIMARY KEY ("id")
+);
+-- Create index "idx_post_revisions_post_number" to table: "post_revisions"
+CREATE UNIQUE INDEX "idx_post_revisions_post_number" ON "public"."post_revisions" ("post_id", "number");
+-- Existing posts start their history with their current state
+INSERT INTO "public"."post_revisions" ("created_at", "post_id", "number", "title", "content", "editor_id")
+SELECT "updated_at", "id", 1, "title", "content", "user_id" FROM "public"."posts";
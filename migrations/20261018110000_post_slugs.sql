@@ -0,0 +1,19 @@
+-- Modify "posts" table
+ALTER TABLE "public"."posts" ADD COLUMN "slug" text NULL;
+-- Give existing posts an ASCII slug from their title, suffixed with the ID to keep it unique
+UPDATE "public"."posts" SET "slug" = COALESCE(NULLIF(trim(both '-' from regexp_replace(lower("title"), '[^a-z0-9]+', '-', 'g')), ''), 'post') || '-' || "id";
+ALTER TABLE "public"."posts" ALTER COLUMN "slug" SET NOT NULL;
+-- Create index "idx_posts_slug" to table: "posts"
+CREATE UNIQUE INDEX "idx_posts_slug" ON "public"."posts" ("slug");
+-- Create "post_slugs" table
+CREATE TABLE "public"."post_slugs" (
+  "id" bigserial NOT NULL,
+  "created_at" timestamptz NULL,
+  "post_id" bigint NOT NULL,
+  "slug" text NOT NULL,
+  PRIMARY KEY ("id")
+);
+-- Create index "idx_post_slugs_post_id" to table: "post_slugs"
+CREATE INDEX "idx_post_slugs_post_id" ON "public"."post_slugs" ("post_id");
+-- Create index "idx_post_slugs_slug" to table: "post_slugs"
+CREATE UNIQUE INDEX "idx_post_slugs_slug" ON "public"."post_slugs" ("slug");
@@ -0,0 +1,21 @@
+-- Modify "posts" table
+ALTER TABLE "public"."posts" ADD COLUMN "content_format" text NOT NULL DEFAULT 'plain', ADD COLUMN "content_html" text NULL, ADD COLUMN "excerpt" text NULL, ADD COLUMN "reading_time" bigint NULL;
+-- Existing posts are plain text, render them like the post service does: escaped paragraphs with line breaks
+UPDATE "public"."posts" AS p SET "content_html" = coalesce((
+  SELECT string_agg('<p>' || replace(replace(replace(replace(replace(replace(s.para, '&', '&amp;'), '''', '&#39;'), '<', '&lt;'), '>', '&gt;'), '"', '&#34;'), E'\n', E'<br/>\n') || '</p>', E'\n' ORDER BY s.n)
+  FROM (
+    SELECT btrim(t.raw, E' \t\n') AS para, t.n
+    FROM regexp_split_to_table(replace(p."content", E'\r\n', E'\n'), E'\n[ \t]*\n') WITH ORDINALITY AS t(raw, n)
+  ) AS s
+  WHERE s.para <> ''
+), '');
+-- Excerpts cut at a word boundary after 160 characters, reading time assumes 200 words per minute
+UPDATE "public"."posts" AS p SET
+  "excerpt" = CASE
+    WHEN char_length(s.text) <= 160 THEN s.text
+    ELSE coalesce(nullif(regexp_replace(left(s.text, 161), '\s*\S*$', ''), ''), left(s.text, 160)) || '…'
+  END,
+  "reading_time" = greatest(1, ceil(array_length(regexp_split_to_array(s.text, ' '), 1) / 200.0))
+FROM (SELECT "id", btrim(regexp_replace("content", '\s+', ' ', 'g')) AS text FROM "public"."posts") AS s
+WHERE s."id" = p."id";
+ALTER TABLE "public"."posts" ALTER COLUMN "content_html" SET NOT NULL, ALTER COLUMN "excerpt" SET NOT NULL, ALTER COLUMN "reading_time" SET NOT NULL;
@@ -0,0 +1,19 @@
+-- Create "comments" table
+CREATE TABLE "public"."comments" (
+  "id" bigserial NOT NULL,
+  "created_at" timestamptz NULL,
+  "updated_at" timestamptz NULL,
+  "deleted_at" timestamptz NULL,
+  "post_id" bigint NOT NULL,
+  "user_id" bigint NOT NULL,
+  "parent_id" bigint NULL,
+  "depth" bigint NOT NULL,
+  "body" text NOT NULL,
+  PRIMARY KEY ("id")
+);
+-- Create index "idx_comments_deleted_at" to table: "comments"
+CREATE INDEX "idx_comments_deleted_at" ON "public"."comments" ("deleted_at");
+-- Create index "idx_comments_parent_id" to table: "comments"
+CREATE INDEX "idx_comments_parent_id" ON "public"."comments" ("parent_id");
+-- Create index "idx_comments_post_id" to table: "comments"
+CREATE INDEX "idx_comments_post_id" ON "public"."comments" ("post_id");
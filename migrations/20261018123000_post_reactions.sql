@@ -0,0 +1,8 @@
+-- Create "post_reactions" table
+CREATE TABLE "public"."post_reactions" (
+  "post_id" bigint NOT NULL,
+  "user_id" bigint NOT NULL,
+  "kind" text NOT NULL,
+  "created_at" timestamptz NULL,
+  PRIMARY KEY ("post_id", "user_id", "kind")
+);
@@ -0,0 +1,7 @@
+-- Create "bookmarks" table
+CREATE TABLE "public"."bookmarks" (
+  "user_id" bigint NOT NULL,
+  "post_id" bigint NOT NULL,
+  "created_at" timestamptz NULL,
+  PRIMARY KEY ("user_id", "post_id")
+);
@@ -0,0 +1,11 @@
+-- Create "follows" table
+CREATE TABLE "public"."follows" (
+  "follower_id" bigint NOT NULL,
+  "followee_id" bigint NOT NULL,
+  "created_at" timestamptz NULL,
+  PRIMARY KEY ("follower_id", "followee_id")
+);
+-- Create index "idx_follows_followee_id_created_at" to table: "follows"
+CREATE INDEX "idx_follows_followee_id_created_at" ON "public"."follows" ("followee_id", "created_at");
+-- Create index "idx_posts_user_id_created_at" to table: "posts"
+CREATE INDEX "idx_posts_user_id_created_at" ON "public"."posts" ("user_id", "created_at");
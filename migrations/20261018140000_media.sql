@@ -0,0 +1,17 @@
+-- Create "media" table
+CREATE TABLE "public"."media" (
+  "id" bigserial NOT NULL,
+  "created_at" timestamptz NULL,
+  "updated_at" timestamptz NULL,
+  "user_id" bigint NOT NULL,
+  "key" text NOT NULL,
+  "filename" text NOT NULL,
+  "content_type" text NOT NULL,
+  "size" bigint NOT NULL,
+  "url" text NOT NULL,
+  PRIMARY KEY ("id")
+);
+-- Create index "idx_media_key" to table: "media"
+CREATE UNIQUE INDEX "idx_media_key" ON "public"."media" ("key");
+-- Create index "idx_media_user_id" to table: "media"
+CREATE INDEX "idx_media_user_id" ON "public"."media" ("user_id");
@@ -0,0 +1,24 @@
+-- Modify "media" table
+ALTER TABLE "public"."media" ADD COLUMN "post_id" bigint NULL, ADD COLUMN "status" text NOT NULL DEFAULT 'ready', ADD COLUMN "width" bigint NOT NULL DEFAULT 0, ADD COLUMN "height" bigint NOT NULL DEFAULT 0, ADD COLUMN "blurhash" text NOT NULL DEFAULT '';
+-- Create index "idx_media_post_id" to table: "media"
+CREATE INDEX "idx_media_post_id" ON "public"."media" ("post_id");
+-- Create index "idx_media_status" to table: "media"
+CREATE INDEX "idx_media_status" ON "public"."media" ("status");
+-- Create "media_variants" table
+CREATE TABLE "public"."media_variants" (
+  "id" bigserial NOT NULL,
+  "created_at" timestamptz NULL,
+  "media_id" bigint NOT NULL,
+  "name" text NOT NULL,
+  "key" text NOT NULL,
+  "url" text NOT NULL,
+  "content_type" text NOT NULL,
+  "width" bigint NOT NULL,
+  "height" bigint NOT NULL,
+  "size" bigint NOT NULL,
+  PRIMARY KEY ("id")
+);
+-- Create index "idx_media_variants_key" to table: "media_variants"
+CREATE UNIQUE INDEX "idx_media_variants_key" ON "public"."media_variants" ("key");
+-- Create index "idx_media_variants_media_id" to table: "media_variants"
+CREATE INDEX "idx_media_variants_media_id" ON "public"."media_variants" ("media_id");
@@ -0,0 +1,4 @@
+-- Modify "profiles" table
+ALTER TABLE "public"."profiles" ADD COLUMN "handle" text NULL, ADD COLUMN "avatar_url" text NOT NULL DEFAULT '', ADD COLUMN "website" text NOT NULL DEFAULT '', ADD COLUMN "location" text NOT NULL DEFAULT '', ADD COLUMN "pronouns" text NOT NULL DEFAULT '', ADD COLUMN "social_links" jsonb NOT NULL DEFAULT '[]';
+-- Create index "idx_profiles_handle" to table: "profiles"
+CREATE UNIQUE INDEX "idx_profiles_handle" ON "public"."profiles" ("handle");
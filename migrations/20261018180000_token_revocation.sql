@@ -0,0 +1,21 @@
+-- Create "revoked_tokens" table
+CREATE TABLE "public"."revoked_tokens" (
+  "id" uuid NOT NULL,
+  "user_id" bigint NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NULL,
+  PRIMARY KEY ("id")
+);
+-- Create index "idx_revoked_tokens_expires_at" to table: "revoked_tokens"
+CREATE INDEX "idx_revoked_tokens_expires_at" ON "public"."revoked_tokens" ("expires_at");
+-- Create index "idx_revoked_tokens_user_id" to table: "revoked_tokens"
+CREATE INDEX "idx_revoked_tokens_user_id" ON "public"."revoked_tokens" ("user_id");
+-- Create "user_revocations" table
+CREATE TABLE "public"."user_revocations" (
+  "user_id" bigint NOT NULL,
+  "revoked_before" timestamptz NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  PRIMARY KEY ("user_id")
+);
+-- Create index "idx_user_revocations_expires_at" to table: "user_revocations"
+CREATE INDEX "idx_user_revocations_expires_at" ON "public"."user_revocations" ("expires_at");
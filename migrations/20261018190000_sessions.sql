@@ -0,0 +1,16 @@
+-- Create "sessions" table
+CREATE TABLE "public"."sessions" (
+  "id" uuid NOT NULL,
+  "user_id" bigint NOT NULL,
+  "user_agent" text NOT NULL DEFAULT '',
+  "ip" text NOT NULL DEFAULT '',
+  "created_at" timestamptz NULL,
+  "last_seen_at" timestamptz NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "revoked_at" timestamptz NULL,
+  PRIMARY KEY ("id")
+);
+-- Create index "idx_sessions_expires_at" to table: "sessions"
+CREATE INDEX "idx_sessions_expires_at" ON "public"."sessions" ("expires_at");
+-- Create index "idx_sessions_user_id" to table: "sessions"
+CREATE INDEX "idx_sessions_user_id" ON "public"."sessions" ("user_id");